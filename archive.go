@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+)
+
+// archiveTagPrefix is prepended to the branch name to form the archive tag
+const archiveTagPrefix = "archive/"
+
+// archiveTagName returns the tag a branch is archived as, e.g. "archive/feature/foo"
+func archiveTagName(branch string) string {
+	return archiveTagPrefix + branch
+}
+
+// createArchiveTag creates a lightweight archive tag at hash. It fails if the tag already exists.
+func createArchiveTag(branch, hash string) error {
+	output, err := newCommand("git", "tag", archiveTagName(branch), hash).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// deleteArchiveTag removes the archive tag of a branch that could not be deleted
+func deleteArchiveTag(branch string) {
+	newCommand("git", "tag", "-d", archiveTagName(branch)).Run()
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupRefPrefix is the namespace holding the tips of deleted branches
+const backupRefPrefix = "refs/git-delete-branch/backup/"
+
+// backupEntry is a deleted branch that can be restored by undo
+type backupEntry struct {
+	// Short name, e.g. "feature/foo"
+	Name string
+	// Full refname the branch had, e.g. "refs/heads/feature/foo"
+	Ref  string
+	Hash string
+	// branch.<name>.remote and branch.<name>.merge of the deleted branch, if it had an upstream
+	UpstreamRemote string
+	UpstreamMerge  string
+}
+
+// backupSession is the set of branches deleted in one run
+type backupSession struct {
+	ID      string
+	Entries []backupEntry
+}
+
+// newBackupSessionID returns the identifier of a run that starts now
+func newBackupSessionID(now time.Time) string {
+	return now.Format("20060102-150405")
+}
+
+// getSessionInfoPath returns the file that keeps the full refnames and upstreams of a session's branches
+func getSessionInfoPath(sessionID string) (string, error) {
+	output, err := newCommand("git", "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(strings.TrimSpace(string(output)), "git-delete-branch", "sessions", sessionID), nil
+}
+
+// createBackup points the backup ref of the branch at its tip and records what undo needs to restore it
+func createBackup(sessionID string, info BranchInfo) error {
+	ref := "refs/heads/" + info.Name
+	if info.Remote {
+		ref = "refs/remotes/" + info.Name
+	}
+	entry := backupEntry{Name: info.Name, Ref: ref, Hash: info.Hash}
+	if !info.Remote {
+		// The upstream configuration is removed together with the branch
+		if output, err := newCommand("git", "config", "branch."+info.Name+".remote").Output(); err == nil {
+			entry.UpstreamRemote = strings.TrimSpace(string(output))
+		}
+		if output, err := newCommand("git", "config", "branch."+info.Name+".merge").Output(); err == nil {
+			entry.UpstreamMerge = strings.TrimSpace(string(output))
+		}
+	}
+
+	output, err := newCommand("git", "update-ref", backupRefPrefix+sessionID+"/"+info.Name, info.Hash).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	path, err := getSessionInfoPath(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "%s\t%s\t%s\t%s\n", entry.Name, entry.Ref, entry.UpstreamRemote, entry.UpstreamMerge)
+	return err
+}
+
+// removeBackup drops the backup ref of a branch that was not deleted after all.
+// The line in the session file is harmless without its ref and is left alone.
+func removeBackup(sessionID, branch string) {
+	newCommand("git", "update-ref", "-d", backupRefPrefix+sessionID+"/"+branch).Run()
+}
+
+// readSessionInfo returns the entries recorded in the session file, keyed by branch name
+func readSessionInfo(sessionID string) map[string]backupEntry {
+	entries := make(map[string]backupEntry)
+	path, err := getSessionInfoPath(sessionID)
+	if err != nil {
+		return entries
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return entries
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		entries[fields[0]] = backupEntry{Name: fields[0], Ref: fields[1], UpstreamRemote: fields[2], UpstreamMerge: fields[3]}
+	}
+	return entries
+}
+
+// listBackupSessions returns the sessions that still have backup refs, most recent first
+func listBackupSessions() ([]backupSession, error) {
+	output, err := newCommand("git", "for-each-ref", "--format=%(refname)%00%(objectname)", backupRefPrefix).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, string(output))
+	}
+
+	sessions := make(map[string]*backupSession)
+	for _, line := range strings.Split(string(output), "\n") {
+		ref, hash, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		id, name, ok := strings.Cut(strings.TrimPrefix(ref, backupRefPrefix), "/")
+		if !ok {
+			continue
+		}
+		if sessions[id] == nil {
+			sessions[id] = &backupSession{ID: id}
+		}
+		sessions[id].Entries = append(sessions[id].Entries, backupEntry{Name: name, Ref: "refs/heads/" + name, Hash: hash})
+	}
+
+	var result []backupSession
+	for _, session := range sessions {
+		info := readSessionInfo(session.ID)
+		for i, entry := range session.Entries {
+			if recorded, ok := info[entry.Name]; ok {
+				recorded.Hash = entry.Hash
+				session.Entries[i] = recorded
+			}
+		}
+		result = append(result, *session)
+	}
+	// Session IDs are timestamps, so they sort chronologically
+	slices.SortFunc(result, func(a, b backupSession) int { return strings.Compare(b.ID, a.ID) })
+	return result, nil
+}
+
+// restoreBackup recreates the branch of the entry together with its upstream configuration
+// and removes its backup ref
+func restoreBackup(sessionID string, entry backupEntry) error {
+	var output []byte
+	var err error
+	if strings.HasPrefix(entry.Ref, "refs/heads/") {
+		output, err = newCommand("git", "branch", entry.Name, entry.Hash).CombinedOutput()
+	} else {
+		// The empty old value makes update-ref fail instead of overwriting an existing ref
+		output, err = newCommand("git", "update-ref", entry.Ref, entry.Hash, "").CombinedOutput()
+	}
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	if entry.UpstreamRemote != "" && entry.UpstreamMerge != "" {
+		newCommand("git", "config", "branch."+entry.Name+".remote", entry.UpstreamRemote).Run()
+		newCommand("git", "config", "branch."+entry.Name+".merge", entry.UpstreamMerge).Run()
+	}
+	removeBackup(sessionID, entry.Name)
+	return nil
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fields requested from git for-each-ref, separated by NUL characters
+var branchInfoFields = []string{
+	"%(refname:short)",
+	"%(upstream:short)",
+	"%(upstream:track)",
+	"%(committerdate:unix)",
+	"%(authorname)",
+	"%(authoremail)",
+	"%(refname)",
+	"%(objectname)",
+	"%(upstream:remotename)",
+	"%(upstream:remoteref)",
+}
+
+// BranchInfo holds the metadata of a branch collected in a single for-each-ref pass
+type BranchInfo struct {
+	// Short name, e.g. "feature/foo" or "origin/feature/foo" for remote-tracking branches
+	Name     string
+	Upstream string
+	Gone     bool
+	// Commits the branch is ahead of and behind its upstream
+	Ahead  int
+	Behind int
+	// Remote of the upstream and the branch name on it, e.g. "origin" and "feature/foo".
+	// The remote is "." when the upstream is a local branch.
+	UpstreamRemote string
+	UpstreamBranch string
+	// Committer date of the branch tip
+	CommitterDate time.Time
+	// Author of the branch tip
+	AuthorName  string
+	AuthorEmail string
+	// Remote is true for remote-tracking branches under refs/remotes/
+	Remote bool
+	// Hash of the branch tip
+	Hash string
+}
+
+// HasRemoteUpstream reports whether the branch tracks a branch that still exists on a remote
+func (b BranchInfo) HasRemoteUpstream() bool {
+	return !b.Remote && !b.Gone && b.UpstreamRemote != "" && b.UpstreamRemote != "." && b.UpstreamBranch != ""
+}
+
+// Keys accepted by --sort, optionally prefixed with "-" for descending order
+var validSortKeys = []string{"committerdate", "authordate", "refname"}
+
+// isValidSortKey reports whether key is one of validSortKeys, with or without a "-" prefix
+func isValidSortKey(key string) bool {
+	return slices.Contains(validSortKeys, strings.TrimPrefix(key, "-"))
+}
+
+// listBranches returns every branch under the given ref namespaces (e.g. "refs/heads/")
+// together with its upstream tracking state. Options are passed through to git for-each-ref
+// (e.g. "--sort=committerdate" or "--contains=<commit>").
+func listBranches(refPrefixes []string, options []string) ([]BranchInfo, error) {
+	format := strings.Join(branchInfoFields, "%00")
+	args := append([]string{"for-each-ref", "--format=" + format}, options...)
+	args = append(args, refPrefixes...)
+	cmd := newCommand("git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, string(output))
+	}
+
+	var branches []BranchInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x00")
+		if len(fields) != len(branchInfoFields) {
+			return nil, fmt.Errorf("unexpected git for-each-ref output: %s", line)
+		}
+		refName := fields[6]
+		remote := strings.HasPrefix(refName, "refs/remotes/")
+		if remote && strings.HasSuffix(refName, "/HEAD") {
+			// Skip symbolic refs such as origin/HEAD
+			continue
+		}
+		committerDate, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected committer date in git for-each-ref output: %s", line)
+		}
+		// The track is e.g. "[ahead 2, behind 1]", "[gone]" or empty when in sync
+		var ahead, behind int
+		for _, part := range strings.Split(strings.Trim(fields[2], "[]"), ", ") {
+			fmt.Sscanf(part, "ahead %d", &ahead)
+			fmt.Sscanf(part, "behind %d", &behind)
+		}
+		branches = append(branches, BranchInfo{
+			Name:           fields[0],
+			Upstream:       fields[1],
+			Gone:           fields[2] == "[gone]",
+			Ahead:          ahead,
+			Behind:         behind,
+			CommitterDate:  time.Unix(committerDate, 0),
+			AuthorName:     fields[4],
+			AuthorEmail:    strings.Trim(fields[5], "<>"),
+			Remote:         remote,
+			Hash:           fields[7],
+			UpstreamRemote: fields[8],
+			UpstreamBranch: strings.TrimPrefix(fields[9], "refs/heads/"),
+		})
+	}
+	return branches, nil
+}
+
+// getMergedBranches returns the set of branches under the given ref namespaces that are merged into base
+func getMergedBranches(base string, refPrefixes []string) (map[string]bool, error) {
+	args := append([]string{"for-each-ref", "--format=%(refname:short)", "--merged=" + base}, refPrefixes...)
+	cmd := newCommand("git", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, string(output))
+	}
+
+	merged := make(map[string]bool)
+	for _, branch := range strings.Split(string(output), "\n") {
+		if branch != "" {
+			merged[branch] = true
+		}
+	}
+	return merged, nil
+}
+
+// getAheadCounts returns, for each branch, the number of commits that are not reachable from base
+func getAheadCounts(base string, branches []string) map[string]int {
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	runConcurrently(len(branches), func(i int) {
+		output, err := newCommand("git", "rev-list", "--count", base+".."+branches[i]).Output()
+		if err != nil {
+			return
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		counts[branches[i]] = count
+		mu.Unlock()
+	})
+	return counts
+}
+
+// Unmerged branches with at least this many commits not on the base are highlighted as risky
+const riskyCommitCount = 10
+
+// uniqueCommitsColor returns the color of a count of commits not on the base: the merged color
+// for none, the warning color for unmerged branches with riskyCommitCount or more
+func uniqueCommitsColor(count int, safe bool) string {
+	switch {
+	case count == 0:
+		return ColorMerged
+	case !safe && count >= riskyCommitCount:
+		return ColorWarning
+	}
+	return ""
+}
+
+// getAheadBehindCounts returns how many commits each branch is ahead of and behind base
+func getAheadBehindCounts(base string, branches []string) map[string][2]int {
+	var mu sync.Mutex
+	counts := make(map[string][2]int)
+	runConcurrently(len(branches), func(i int) {
+		output, err := newCommand("git", "rev-list", "--left-right", "--count", branches[i]+"..."+base, "--").Output()
+		if err != nil {
+			return
+		}
+		var ahead, behind int
+		if _, err := fmt.Sscanf(string(output), "%d %d", &ahead, &behind); err != nil {
+			return
+		}
+		mu.Lock()
+		counts[branches[i]] = [2]int{ahead, behind}
+		mu.Unlock()
+	})
+	return counts
+}
+
+// getPushedBranches returns the subset of branches whose tip commit is reachable from a remote-tracking ref
+func getPushedBranches(branches []string) map[string]bool {
+	var mu sync.Mutex
+	pushed := make(map[string]bool)
+	runConcurrently(len(branches), func(i int) {
+		// Lists at most one commit of the branch that no remote-tracking ref contains
+		output, err := newCommand("git", "rev-list", "-n", "1", branches[i], "--not", "--remotes").Output()
+		if err != nil || strings.TrimSpace(string(output)) != "" {
+			return
+		}
+		mu.Lock()
+		pushed[branches[i]] = true
+		mu.Unlock()
+	})
+	return pushed
+}
+
+// getUnpushedBranches returns the branches whose tip commit is not reachable from any remote-tracking ref.
+// A single rev-list lists every commit missing on the remotes, and a tip is among them exactly when it is unpushed.
+func getUnpushedBranches(infos []BranchInfo) map[string]bool {
+	unpushed := make(map[string]bool)
+	if len(infos) == 0 {
+		return unpushed
+	}
+	args := []string{"rev-list"}
+	for _, info := range infos {
+		args = append(args, info.Hash)
+	}
+	args = append(args, "--not", "--remotes")
+	output, err := newCommand("git", args...).Output()
+	if err != nil {
+		return unpushed
+	}
+	missing := make(map[string]bool)
+	for _, hash := range strings.Fields(string(output)) {
+		missing[hash] = true
+	}
+	for _, info := range infos {
+		if missing[info.Hash] {
+			unpushed[info.Name] = true
+		}
+	}
+	return unpushed
+}
+
+// readBranchNames reads branch names separated by sep from r. Names are trimmed, empty entries
+// and duplicates are dropped, and the markers printed by git branch ("* ", "+ ") are removed.
+func readBranchNames(r io.Reader, sep byte) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(string(data), string(sep)) {
+		name = strings.TrimSpace(name)
+		name = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(name, "* "), "+ "))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// getBranchDescriptions returns the branch.<name>.description of every branch that has one
+func getBranchDescriptions() map[string]string {
+	descriptions := make(map[string]string)
+	// -z separates the key from the value with a newline and entries with NUL,
+	// so multi-line descriptions can be told apart
+	output, err := newCommand("git", "config", "-z", "--get-regexp", `^branch\..*\.description$`).Output()
+	if err != nil {
+		// git config exits with 1 when no description is set
+		return descriptions
+	}
+	for _, entry := range strings.Split(string(output), "\x00") {
+		key, value, ok := strings.Cut(entry, "\n")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), ".description")
+		descriptions[name] = strings.TrimSpace(value)
+	}
+	return descriptions
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+// findDuplicateBranches maps every local branch whose tip is the same commit as another local
+// branch to the branch that is kept. The preferred branches (e.g. the current branch) are kept
+// first, otherwise the first branch in the list survives.
+func findDuplicateBranches(branches []BranchInfo, preferred func(string) bool) map[string]string {
+	byHash := make(map[string][]string)
+	for _, branch := range branches {
+		if !branch.Remote {
+			byHash[branch.Hash] = append(byHash[branch.Hash], branch.Name)
+		}
+	}
+
+	duplicates := make(map[string]string)
+	for _, names := range byHash {
+		if len(names) < 2 {
+			continue
+		}
+		survivor := names[0]
+		if i := slices.IndexFunc(names, preferred); i >= 0 {
+			survivor = names[i]
+		}
+		for _, name := range names {
+			if name != survivor {
+				duplicates[name] = survivor
+			}
+		}
+	}
+	return duplicates
+}
+
+// findContainingBranches maps every given branch whose tip is reachable from another local branch
+// (that points at a different commit) to the first such branch
+func findContainingBranches(branches []BranchInfo) map[string]string {
+	var mu sync.Mutex
+	containedIn := make(map[string]string)
+	runConcurrently(len(branches), func(i int) {
+		output, err := newCommand("git", "for-each-ref", "--format=%(refname:short) %(objectname)",
+			"--contains="+branches[i].Hash, "refs/heads/").Output()
+		if err != nil {
+			return
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			name, hash, _ := strings.Cut(line, " ")
+			if name != "" && hash != branches[i].Hash {
+				mu.Lock()
+				containedIn[branches[i].Name] = name
+				mu.Unlock()
+				return
+			}
+		}
+	})
+	return containedIn
+}
+
+// getDivergenceDates returns the commit date of the merge-base of each branch with base.
+// Branches without a common ancestor are reported in orphans instead.
+func getDivergenceDates(base string, branches []string) (dates map[string]time.Time, orphans map[string]bool) {
+	var mu sync.Mutex
+	dates = make(map[string]time.Time)
+	orphans = make(map[string]bool)
+	mergeBases := make(map[string]string)
+	runConcurrently(len(branches), func(i int) {
+		mergeBase, err := newCommand("git", "merge-base", base, branches[i]).Output()
+		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
+			mu.Lock()
+			orphans[branches[i]] = true
+			mu.Unlock()
+			return
+		}
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		mergeBases[branches[i]] = strings.TrimSpace(string(mergeBase))
+		mu.Unlock()
+	})
+	if len(mergeBases) == 0 {
+		return dates, orphans
+	}
+
+	// The dates of all the merge-bases are read at once. Branches often share one, and the
+	// hashes go to stdin so that no command line gets too long.
+	hashes := slices.Sorted(maps.Values(mergeBases))
+	cmd := newCommand("git", "log", "--no-walk", "--stdin", "--format=%H %ct")
+	cmd.Stdin = strings.NewReader(strings.Join(slices.Compact(hashes), "\n") + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return dates, orphans
+	}
+	commitDates := make(map[string]time.Time)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		hash, date, _ := strings.Cut(line, " ")
+		if timestamp, err := strconv.ParseInt(date, 10, 64); err == nil {
+			commitDates[hash] = time.Unix(timestamp, 0)
+		}
+	}
+	for branch, mergeBase := range mergeBases {
+		if date, ok := commitDates[mergeBase]; ok {
+			dates[branch] = date
+		}
+	}
+	return dates, orphans
+}
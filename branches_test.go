@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// gitRun runs git in the working directory and fails the test when it does
+func gitRun(t *testing.T, args ...string) {
+	t.Helper()
+	if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+}
+
+// commitRepo makes gitConfigRepo a repository that commits can be made in
+func commitRepo(t *testing.T) {
+	t.Helper()
+	gitConfigRepo(t)
+	t.Setenv("GIT_AUTHOR_NAME", "A")
+	t.Setenv("GIT_AUTHOR_EMAIL", "a@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "A")
+	t.Setenv("GIT_COMMITTER_EMAIL", "a@example.com")
+}
+
+func TestAheadBehindCountsIncludeCurrent(t *testing.T) {
+	commitRepo(t)
+	gitRun(t, "checkout", "-q", "-b", "main")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "root")
+	gitRun(t, "checkout", "-q", "-b", "feat")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "feat")
+	gitRun(t, "checkout", "-q", "main")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "main 1")
+	gitRun(t, "commit", "-q", "--allow-empty", "-m", "main 2")
+	gitRun(t, "checkout", "-q", "feat")
+
+	tests := []struct {
+		name           string
+		base           string
+		includeCurrent bool
+		wantBase       string
+		want           [2]int
+	}{
+		// The current branch is counted against what it is checked for being merged into
+		{name: "include current", includeCurrent: true, wantBase: "main", want: [2]int{1, 2}},
+		{name: "without include current", wantBase: "HEAD", want: [2]int{0, 0}},
+		{name: "base wins", base: "feat", includeCurrent: true, wantBase: "feat", want: [2]int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mergeBase := getMergeBase(tt.base, tt.includeCurrent, "feat")
+			if mergeBase != tt.wantBase {
+				t.Fatalf("getMergeBase(%q, %v, \"feat\") = %q, want %q", tt.base, tt.includeCurrent, mergeBase, tt.wantBase)
+			}
+			counts := getAheadBehindCounts(mergeBase, []string{"feat"})
+			if counts["feat"] != tt.want {
+				t.Errorf("ahead and behind of feat against %s = %v, want %v", mergeBase, counts["feat"], tt.want)
+			}
+		})
+	}
+}
+
+func TestDivergenceDates(t *testing.T) {
+	commitRepo(t)
+	commitAt := func(message string, unix int64) {
+		t.Helper()
+		t.Setenv("GIT_COMMITTER_DATE", time.Unix(unix, 0).Format(time.RFC3339))
+		gitRun(t, "commit", "-q", "--allow-empty", "-m", message)
+	}
+	gitRun(t, "checkout", "-q", "-b", "main")
+	commitAt("root", 1_000_000_000)
+	commitAt("fork point", 1_100_000_000)
+	// Two branches off the same commit share their merge-base
+	gitRun(t, "branch", "one")
+	gitRun(t, "branch", "two")
+	commitAt("main", 1_200_000_000)
+	gitRun(t, "checkout", "-q", "-b", "three")
+	commitAt("three", 1_300_000_000)
+	gitRun(t, "checkout", "-q", "--orphan", "orphan")
+	commitAt("orphan", 1_400_000_000)
+	gitRun(t, "checkout", "-q", "main")
+
+	dates, orphans := getDivergenceDates("main", []string{"one", "two", "three", "orphan"})
+	want := map[string]int64{"one": 1_100_000_000, "two": 1_100_000_000, "three": 1_200_000_000}
+	if len(dates) != len(want) {
+		t.Errorf("dates = %v, want %v", dates, want)
+	}
+	for branch, unix := range want {
+		if got := dates[branch]; got.Unix() != unix {
+			t.Errorf("divergence date of %s = %v, want %v", branch, got, time.Unix(unix, 0))
+		}
+	}
+	if len(orphans) != 1 || !orphans["orphan"] {
+		t.Errorf("orphans = %v, want orphan", orphans)
+	}
+}
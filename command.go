@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// verboseCommands makes every command log its command line and exit status to stderr
+var verboseCommands bool
+
+// loggedCmd is an exec.Cmd that is logged when verboseCommands is set
+type loggedCmd struct {
+	*exec.Cmd
+}
+
+// newCommand is exec.Command for commands that show up in the --verbose log
+func newCommand(name string, args ...string) *loggedCmd {
+	return &loggedCmd{exec.Command(name, args...)}
+}
+
+// commandLine returns the command as it could be pasted into a shell
+func (c *loggedCmd) commandLine() string {
+	words := []string{c.Args[0]}
+	for _, arg := range c.Args[1:] {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+			arg = shellQuote(arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// logged runs the command with run, logging before and after it when verbose
+func (c *loggedCmd) logged(run func() error) error {
+	if !verboseCommands {
+		return run()
+	}
+	line := c.commandLine()
+	fmt.Fprintf(os.Stderr, "[run] %s\n", line)
+	start := time.Now()
+	err := run()
+	status := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		status = exitErr.ExitCode()
+	} else if err != nil {
+		status = -1
+	}
+	fmt.Fprintf(os.Stderr, "[exit %d, %s] %s\n", status, time.Since(start).Round(time.Millisecond), line)
+	return err
+}
+
+func (c *loggedCmd) Run() error {
+	return c.logged(c.Cmd.Run)
+}
+
+func (c *loggedCmd) Output() ([]byte, error) {
+	var output []byte
+	err := c.logged(func() (err error) {
+		output, err = c.Cmd.Output()
+		return err
+	})
+	return output, err
+}
+
+func (c *loggedCmd) CombinedOutput() ([]byte, error) {
+	var output []byte
+	err := c.logged(func() (err error) {
+		output, err = c.Cmd.CombinedOutput()
+		return err
+	})
+	return output, err
+}
@@ -0,0 +1,27 @@
+package main
+
+import "sync"
+
+// Number of git processes allowed to run at the same time
+const maxConcurrentGitProcesses = 8
+
+// runConcurrently calls fn for every index in [0, n) using a bounded pool of goroutines
+func runConcurrently(n int, fn func(i int)) {
+	runConcurrentlyLimit(n, maxConcurrentGitProcesses, fn)
+}
+
+// runConcurrentlyLimit is runConcurrently with at most limit calls of fn running at once
+func runConcurrentlyLimit(n, limit int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(limit, 1))
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// configSection is the git config section holding the defaults, e.g. delete-branch.base
+const configSection = "delete-branch."
+
+// envPrefix starts the environment variables holding defaults, e.g. GIT_DELETE_BRANCH_BASE
+const envPrefix = "GIT_DELETE_BRANCH_"
+
+// Flags that are not options a user would want a default for
+var noDefaultFlags = map[string]bool{
+	"h": true, "help": true, "get-log": true, "toggle-force": true, "preview-mode-file": true, "cycle-preview-mode": true,
+}
+
+// configKey returns how a flag name looks as a git config key, which git lowercases:
+// "pre-delete-cmd" is read from delete-branch.preDeleteCmd
+func configKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "-", ""))
+}
+
+// envName returns the environment variable of a flag name, e.g. GIT_DELETE_BRANCH_PRE_DELETE_CMD
+func envName(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// normalizeBool turns the boolean spellings git accepts into ones flag.Set accepts.
+// A key without a value means true in git.
+func normalizeBool(value string) string {
+	switch strings.ToLower(value) {
+	case "", "yes", "on":
+		return "true"
+	case "no", "off":
+		return "false"
+	}
+	return value
+}
+
+// applyDefaults sets the flags that were not given on the command line from the environment,
+// then from the delete-branch.* git config. git lists the system, global and repository
+// config in that order, so the repository wins for single values and repeatable flags
+// collect the values of all of them. It returns the unknown config keys and the values that were invalid.
+func applyDefaults(flags *flag.FlagSet) (unknownKeys, invalid []string) {
+	// -D and --force share a value, giving either one counts for both
+	given := make(map[flag.Value]bool)
+	flags.Visit(func(f *flag.Flag) { given[f.Value] = true })
+
+	// Long names come first, so a config key never picks a single-letter alias
+	byKey := make(map[string]*flag.Flag)
+	flags.VisitAll(func(f *flag.Flag) {
+		if !noDefaultFlags[f.Name] && len(f.Name) > 1 {
+			byKey[configKey(f.Name)] = f
+		}
+	})
+
+	set := func(f *flag.Flag, value, source string) {
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+			value = normalizeBool(value)
+		}
+		if err := f.Value.Set(value); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s=%q: %v", source, value, err))
+		}
+	}
+
+	fromEnv := make(map[flag.Value]bool)
+	for _, f := range byKey {
+		if value, ok := os.LookupEnv(envName(f.Name)); ok && !given[f.Value] && !fromEnv[f.Value] {
+			set(f, value, envName(f.Name))
+			fromEnv[f.Value] = true
+		}
+	}
+
+	output, err := newCommand("git", "config", "--get-regexp", `^delete-branch\.`).Output()
+	if err != nil {
+		// git exits with 1 when no key matches
+		return nil, invalid
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		if strings.HasPrefix(key, colorConfigSection) {
+			// Read by applyTheme
+			continue
+		}
+		name := strings.TrimPrefix(key, configSection)
+		f, ok := byKey[name]
+		if !ok {
+			unknownKeys = append(unknownKeys, key)
+			continue
+		}
+		if given[f.Value] || fromEnv[f.Value] {
+			continue
+		}
+		set(f, value, key)
+	}
+	return unknownKeys, invalid
+}
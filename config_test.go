@@ -0,0 +1,135 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// gitConfigRepo makes a temp repository the working directory, with a home of its own so only
+// the global config written by the test is read. It returns the path of that global config.
+func gitConfigRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home := t.TempDir()
+	repo := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+	for _, name := range []string{envName("base"), envName("force"), envName("lang")} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	t.Chdir(repo)
+	return filepath.Join(home, ".gitconfig")
+}
+
+// gitConfig sets a key in the repository config, or with global in the global config
+func gitConfig(t *testing.T, global bool, key, value string) {
+	t.Helper()
+	args := []string{"config"}
+	if global {
+		args = append(args, "--global")
+	}
+	if output, err := exec.Command("git", append(args, key, value)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+}
+
+// testFlags returns a flag set with a few of the real flags, parsed from args
+func testFlags(t *testing.T, args ...string) (*flag.FlagSet, *string, *bool, *string) {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	base := flags.String("base", "", "")
+	force := flags.Bool("force", false, "")
+	lang := flags.String("lang", "", "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags, base, force, lang
+}
+
+func TestApplyDefaultsPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		env    string
+		repo   string
+		global string
+		want   string
+	}{
+		{name: "built-in", want: ""},
+		{name: "global config", global: "global", want: "global"},
+		{name: "repo config over global config", repo: "repo", global: "global", want: "repo"},
+		{name: "env over repo config", env: "env", repo: "repo", global: "global", want: "env"},
+		{name: "flag over env", args: []string{"-base", "flag"}, env: "env", repo: "repo", global: "global", want: "flag"},
+		{name: "flag over repo config", args: []string{"-base", "flag"}, repo: "repo", want: "flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitConfigRepo(t)
+			if tt.env != "" {
+				t.Setenv(envName("base"), tt.env)
+			}
+			if tt.repo != "" {
+				gitConfig(t, false, "delete-branch.base", tt.repo)
+			}
+			if tt.global != "" {
+				gitConfig(t, true, "delete-branch.base", tt.global)
+			}
+			flags, base, _, _ := testFlags(t, tt.args...)
+			unknown, invalid := applyDefaults(flags)
+			if len(unknown) > 0 || len(invalid) > 0 {
+				t.Fatalf("applyDefaults reported unknown keys %q and invalid values %q", unknown, invalid)
+			}
+			if *base != tt.want {
+				t.Errorf("base = %q, want %q", *base, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDefaultsValues(t *testing.T) {
+	gitConfigRepo(t)
+	gitConfig(t, false, "delete-branch.force", "yes")
+	gitConfig(t, true, "delete-branch.lang", "ja")
+	gitConfig(t, false, "delete-branch.nosuchoption", "1")
+
+	flags, _, force, lang := testFlags(t)
+	unknown, invalid := applyDefaults(flags)
+	if !*force {
+		t.Error("delete-branch.force=yes did not set --force")
+	}
+	if *lang != "ja" {
+		t.Errorf("lang = %q, want \"ja\"", *lang)
+	}
+	if !slices.Equal(unknown, []string{"delete-branch.nosuchoption"}) {
+		t.Errorf("unknown keys = %q, want delete-branch.nosuchoption", unknown)
+	}
+	if len(invalid) > 0 {
+		t.Errorf("invalid values = %q, want none", invalid)
+	}
+}
+
+func TestApplyDefaultsInvalid(t *testing.T) {
+	gitConfigRepo(t)
+	gitConfig(t, false, "delete-branch.force", "maybe")
+
+	flags, _, force, _ := testFlags(t)
+	_, invalid := applyDefaults(flags)
+	if *force {
+		t.Error("an invalid delete-branch.force set --force")
+	}
+	if len(invalid) != 1 {
+		t.Errorf("invalid values = %q, want the one of delete-branch.force", invalid)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// deleteResult is the outcome of deleting one branch
+type deleteResult struct {
+	// Output holds the lines git printed about the branch
+	Output string
+	Err    error
+}
+
+// deleteBranches deletes the branches with a single git branch invocation using the delete
+// flag (-d, -D or -rd) and attributes git's output back to the individual branches.
+// Branches whose failure cannot be attributed are deleted again one by one.
+func deleteBranches(deleteFlag string, branches []string) map[string]deleteResult {
+	results := make(map[string]deleteResult)
+	if len(branches) == 0 {
+		return results
+	}
+	output, err := uninterruptibleCommand(append([]string{"branch", deleteFlag}, branches...)...).CombinedOutput()
+	if len(branches) == 1 {
+		results[branches[0]] = deleteResult{Output: string(output), Err: err}
+		return results
+	}
+
+	lines := attributeOutputLines(string(output), branches)
+	if err == nil {
+		for _, branch := range branches {
+			results[branch] = deleteResult{Output: lines[branch]}
+		}
+		return results
+	}
+
+	// git exits non-zero when any branch failed, so check which ones are still there
+	refPrefix := "refs/heads/"
+	if deleteFlag == "-rd" {
+		refPrefix = "refs/remotes/"
+	}
+	remaining := existingRefs(refPrefix)
+	for _, branch := range branches {
+		switch {
+		case !remaining[refPrefix+branch]:
+			results[branch] = deleteResult{Output: lines[branch]}
+		case lines[branch] != "":
+			results[branch] = deleteResult{Output: lines[branch], Err: err}
+		default:
+			output, err := uninterruptibleCommand("branch", deleteFlag, branch).CombinedOutput()
+			results[branch] = deleteResult{Output: string(output), Err: err}
+		}
+	}
+	return results
+}
+
+// attributeOutputLines groups the lines of git branch output by the branch they mention.
+// Indented continuation lines belong to the branch of the previous line.
+func attributeOutputLines(output string, branches []string) map[string]string {
+	lines := make(map[string]string)
+	last := ""
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, " ") && last != "" {
+			lines[last] += line + "\n"
+			continue
+		}
+		last = ""
+		// Branch names cannot contain spaces, so they appear as single words, possibly quoted
+		for _, word := range strings.Fields(line) {
+			word = strings.Trim(word, "'\"().,:")
+			if slices.Contains(branches, word) {
+				last = word
+				break
+			}
+		}
+		if last != "" {
+			lines[last] += line + "\n"
+		}
+	}
+	return lines
+}
+
+// existingRefs returns the full refnames under the prefix
+func existingRefs(prefix string) map[string]bool {
+	refs := make(map[string]bool)
+	output, err := newCommand("git", "for-each-ref", "--format=%(refname)", prefix).Output()
+	if err != nil {
+		return refs
+	}
+	for _, ref := range strings.Split(string(output), "\n") {
+		if ref != "" {
+			refs[ref] = true
+		}
+	}
+	return refs
+}
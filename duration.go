@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAgeDuration parses a Go duration (e.g. "36h") or a day/week shorthand (e.g. "90d", "12w")
+func parseAgeDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(value)
+}
+
+// parseTimeThreshold parses either a date ("2024-05-12") or an age duration ("90d"),
+// in which case the threshold is that long ago
+func parseTimeThreshold(value string) (time.Time, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	age, err := parseAgeDuration(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-age), nil
+}
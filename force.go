@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// forceTag marks fzf lines that were toggled for force deletion with ctrl-f. It is only shown,
+// the force file holds the branches that are really toggled.
+const forceTag = "[FORCE]"
+
+// setForceTag adds the force tag right after the branch name in the text of the line, or with
+// forced false removes the one found there. Other text of the line, such as the description of
+// the branch, is left alone.
+func setForceTag(line string, forced bool) string {
+	branch, text := lineKey(line), lineText(line)
+	i := strings.Index(text, branch)
+	if i < 0 {
+		return line
+	}
+	end := i + len(branch)
+	rest, tagged := strings.CutPrefix(text[end:], " "+forceTag)
+	if forced == tagged {
+		return line
+	}
+	if forced {
+		rest = " " + forceTag + text[end:]
+	}
+	return keyedLine(branch, text[:end]+rest)
+}
+
+// readForceFile returns the branches toggled for force deletion, one per line of the file
+func readForceFile(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	forced := make(map[string]bool)
+	for _, branch := range strings.Split(string(data), "\n") {
+		if branch != "" {
+			forced[branch] = true
+		}
+	}
+	return forced, nil
+}
+
+// toggleForceInFile toggles force deletion of the branch in the force file and shows the
+// change on its line of the fzf items file
+func toggleForceInFile(itemsPath, forcePath, branch string) error {
+	if branch == "" {
+		return nil
+	}
+	forced, err := readForceFile(forcePath)
+	if err != nil {
+		return err
+	}
+	forced[branch] = !forced[branch]
+	var branches []string
+	for name, on := range forced {
+		if on {
+			branches = append(branches, name)
+		}
+	}
+	slices.Sort(branches)
+	if err := os.WriteFile(forcePath, []byte(strings.Join(branches, "\n")), 0o600); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(itemsPath)
+	if err != nil {
+		return err
+	}
+	items := strings.Split(string(data), "\n")
+	for i, item := range items {
+		if lineKey(item) == branch {
+			items[i] = setForceTag(item, forced[branch])
+		}
+	}
+	return os.WriteFile(itemsPath, []byte(strings.Join(items, "\n")), 0o600)
+}
+
+// askDeleteFailure asks what to do about a branch that git failed to delete.
+// ahead is the number of commits not on base when the branch is not fully merged, or -1.
+// It returns "skip", "retry", "force", "force-all" or "abort".
+func askDeleteFailure(localizer *i18n.Localizer, branch, base string, ahead int, canForce bool, opts []survey.AskOpt) string {
+	message := localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "DeleteFailedPrompt",
+		TemplateData: map[string]interface{}{"Branch": branch},
+	})
+	if ahead >= 0 {
+		message = localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "NotFullyMergedPrompt",
+			TemplateData: map[string]interface{}{"Branch": branch, "Count": ahead, "Base": base},
+		})
+	}
+
+	choices := []string{"skip", "retry"}
+	if canForce {
+		choices = append(choices, "force", "force-all")
+	}
+	choices = append(choices, "abort")
+	messageIDs := map[string]string{
+		"skip":      "DeleteFailedSkip",
+		"retry":     "DeleteFailedRetry",
+		"force":     "DeleteFailedForce",
+		"force-all": "DeleteFailedForceAll",
+		"abort":     "DeleteFailedAbort",
+	}
+	var options []string
+	for _, choice := range choices {
+		options = append(options, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: messageIDs[choice]}))
+	}
+
+	prompt := &survey.Select{Message: message, Options: options, Default: options[0]}
+	var index int
+	if err := survey.AskOne(prompt, &index, opts...); err != nil {
+		return "abort"
+	}
+	return choices[index]
+}
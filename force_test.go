@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetForceTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		forced bool
+		want   string
+	}{
+		{"adds the tag", keyedLine("feat", "feat (merged)"), true, keyedLine("feat", "feat [FORCE] (merged)")},
+		{"removes the tag", keyedLine("feat", "feat [FORCE] (merged)"), false, keyedLine("feat", "feat (merged)")},
+		{"keeps a tag", keyedLine("feat", "feat [FORCE] (merged)"), true, keyedLine("feat", "feat [FORCE] (merged)")},
+		{"tag in the description is not the mark", keyedLine("feat", "feat (merged) · fix [FORCE] pushes"), false, keyedLine("feat", "feat (merged) · fix [FORCE] pushes")},
+		{"adds the tag before a description with one", keyedLine("feat", "feat (merged) · fix [FORCE] pushes"), true, keyedLine("feat", "feat [FORCE] (merged) · fix [FORCE] pushes")},
+		{"colored name", keyedLine("feat", ColorMerged+"feat (merged)"), true, keyedLine("feat", ColorMerged+"feat [FORCE] (merged)")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setForceTag(tt.line, tt.forced); got != tt.want {
+				t.Errorf("setForceTag(%q, %v) = %q, want %q", tt.line, tt.forced, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleForceInFile(t *testing.T) {
+	dir := t.TempDir()
+	itemsPath := filepath.Join(dir, "items")
+	forcePath := filepath.Join(dir, "force")
+	lines := []string{
+		keyedLine("feat", "feat (merged) · fix [FORCE] pushes"),
+		keyedLine("", "──"),
+		keyedLine("fix", "fix (unmerged)"),
+	}
+	if err := os.WriteFile(itemsPath, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(forcePath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A description that reads [FORCE] is not a toggle
+	forced, err := readForceFile(forcePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(forced) > 0 {
+		t.Fatalf("forced = %v before any toggle, want none", forced)
+	}
+
+	check := func(wantForced map[string]bool, wantLines ...string) {
+		t.Helper()
+		forced, err := readForceFile(forcePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(forced) != len(wantForced) {
+			t.Errorf("forced = %v, want %v", forced, wantForced)
+		}
+		for branch := range wantForced {
+			if !forced[branch] {
+				t.Errorf("forced = %v, want %v", forced, wantForced)
+			}
+		}
+		data, err := os.ReadFile(itemsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != strings.Join(wantLines, "\n") {
+			t.Errorf("items file = %q, want %q", got, strings.Join(wantLines, "\n"))
+		}
+	}
+
+	if err := toggleForceInFile(itemsPath, forcePath, "feat"); err != nil {
+		t.Fatal(err)
+	}
+	check(map[string]bool{"feat": true}, keyedLine("feat", "feat [FORCE] (merged) · fix [FORCE] pushes"), lines[1], lines[2])
+
+	if err := toggleForceInFile(itemsPath, forcePath, "fix"); err != nil {
+		t.Fatal(err)
+	}
+	check(map[string]bool{"feat": true, "fix": true}, keyedLine("feat", "feat [FORCE] (merged) · fix [FORCE] pushes"), lines[1], keyedLine("fix", "fix [FORCE] (unmerged)"))
+
+	if err := toggleForceInFile(itemsPath, forcePath, "feat"); err != nil {
+		t.Fatal(err)
+	}
+	check(map[string]bool{"fix": true}, lines[0], lines[1], keyedLine("fix", "fix [FORCE] (unmerged)"))
+
+	// Dividers have no key and cannot be toggled
+	if err := toggleForceInFile(itemsPath, forcePath, ""); err != nil {
+		t.Fatal(err)
+	}
+	check(map[string]bool{"fix": true}, lines[0], lines[1], keyedLine("fix", "fix [FORCE] (unmerged)"))
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/core"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// fzfFieldSeparator ends the hidden first field of an fzf line, the key the selection is mapped
+// back with. Branch names cannot contain tabs.
+const fzfFieldSeparator = "\t"
+
+// fzfKeyArgs make fzf show and search only the text after the key. Commands run by fzf get
+// the key as {1}.
+var fzfKeyArgs = []string{"--delimiter", fzfFieldSeparator, "--with-nth", "2.."}
+
+// keyedLine returns an fzf line showing text that is selected as key. Lines that cannot be
+// selected, such as dividers, have an empty key.
+func keyedLine(key, text string) string {
+	return key + fzfFieldSeparator + text
+}
+
+// lineKey returns the key of an fzf line. A line without a key is its own key.
+func lineKey(line string) string {
+	key, _, _ := strings.Cut(line, fzfFieldSeparator)
+	return key
+}
+
+// lineText returns the text fzf shows for a line
+func lineText(line string) string {
+	if _, text, ok := strings.Cut(line, fzfFieldSeparator); ok {
+		return text
+	}
+	return line
+}
+
+// errFzfCancelled is returned by runFzf when the user pressed Ctrl+C or Esc
+var errFzfCancelled = errors.New("fzf selection cancelled")
+
+// runFzf feeds items to fzf started with args and returns the selected lines
+func runFzf(args []string, items []string) ([]string, error) {
+	fzfCmd := newCommand("fzf", args...)
+	fzfCmd.Stderr = os.Stderr // Show fzf errors
+
+	// Pass branches to fzf stdin
+	fzfStdin, err := fzfCmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating stdin pipe for fzf: %w", err)
+	}
+	go func() {
+		defer fzfStdin.Close()
+		for _, item := range items {
+			fmt.Fprintln(fzfStdin, item)
+		}
+	}()
+
+	// Capture fzf stdout
+	var fzfStdout bytes.Buffer
+	fzfCmd.Stdout = &fzfStdout
+
+	// Run fzf
+	err = fzfCmd.Run()
+	if err != nil {
+		// fzf returns non-zero exit code if no selection or cancelled
+		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 130 {
+			// User cancelled (Ctrl+C or Esc)
+			return nil, errFzfCancelled
+		}
+		return nil, err
+	}
+
+	// Lines start with their key, but the query printed by --print-query may be empty
+	selected := strings.TrimRight(fzfStdout.String(), "\n")
+	if selected == "" {
+		return nil, nil
+	}
+	return strings.Split(selected, "\n"), nil
+}
+
+// runFzfWithQuery is runFzf that also returns the query the lines were selected with
+func runFzfWithQuery(args []string, items []string) (string, []string, error) {
+	lines, err := runFzf(append(args, "--print-query"), items)
+	if err != nil || len(lines) == 0 {
+		return "", nil, err
+	}
+	return lines[0], lines[1:], nil
+}
+
+// fzfPreselectBind returns the --bind spec that selects the lines whose key is in preselected
+// once the input is loaded, then types the query. The positions count the unfiltered lines, so
+// the query has to come after them. Needs fzf 0.40 or later.
+func fzfPreselectBind(items []string, preselected map[string]bool, query string) string {
+	actions := []string{"unbind(load)"}
+	for i, item := range items {
+		if key := lineKey(item); key != "" && preselected[key] {
+			actions = append(actions, fmt.Sprintf("pos(%d)", i+1), "toggle")
+		}
+	}
+	actions = append(actions, "first")
+	if query != "" {
+		// The colon form takes the rest of the spec, so the query needs no escaping
+		actions = append(actions, "change-query:"+query)
+	}
+	return "load:" + strings.Join(actions, "+")
+}
+
+// fzfKeyHelp is an entry of the key help in the fzf header
+type fzfKeyHelp struct {
+	key       string
+	messageID string
+}
+
+// fzfKeyHelps are the entries of the key help in the fzf header, in the order they are shown
+var fzfKeyHelps = []fzfKeyHelp{
+	{"tab", "FzfKeySelect"},
+	{"ctrl-a", "FzfKeySelectAll"},
+	{"ctrl-d", "FzfKeyDeselectAll"},
+	{"enter", "FzfKeyConfirm"},
+	{"ctrl-f", "FzfKeyForce"},
+	{"ctrl-o", "FzfKeyPreview"},
+}
+
+// fzfHeaderKeys returns the key help of the fzf header. Only the keys in bound are named.
+func fzfHeaderKeys(localizer *i18n.Localizer, bound map[string]bool) string {
+	var helps []string
+	for _, help := range fzfKeyHelps {
+		if bound[help.key] {
+			text, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: help.messageID})
+			helps = append(helps, text)
+		}
+	}
+	return strings.Join(helps, ", ")
+}
+
+// fzfReboundKeys returns the keys that --bind options in args bind, e.g. ctrl-d for
+// --bind=ctrl-d:page-down. Actions are not looked into, so a comma in one may be taken for the
+// start of another binding.
+func fzfReboundKeys(args []string) map[string]bool {
+	keys := make(map[string]bool)
+	for i, arg := range args {
+		spec, ok := strings.CutPrefix(arg, "--bind=")
+		if !ok {
+			if arg != "--bind" || i+1 >= len(args) {
+				continue
+			}
+			spec = args[i+1]
+		}
+		for _, binding := range strings.Split(spec, ",") {
+			if key, _, ok := strings.Cut(binding, ":"); ok {
+				keys[strings.ToLower(strings.TrimSpace(key))] = true
+			}
+		}
+	}
+	return keys
+}
+
+// fzfVersionAtLeast reports whether the installed fzf is at least version major.minor,
+// for options older versions reject
+func fzfVersionAtLeast(major, minor int) bool {
+	output, err := newCommand("fzf", "--version").Output()
+	if err != nil {
+		return false
+	}
+	// e.g. "0.44.1 (d7d2ac3)"
+	var gotMajor, gotMinor int
+	if _, err := fmt.Sscanf(string(output), "%d.%d", &gotMajor, &gotMinor); err != nil {
+		return false
+	}
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// runSurveyPicker lets the user pick items with a survey multi-select, for when fzf is not available.
+// The items are fzf lines, of which only the text is shown. Typing filters the items by their text
+// without colors. The lines whose key is in preselected start out selected. It returns the selected
+// lines, or errFzfCancelled when cancelled.
+func runSurveyPicker(message string, items []string, preselected map[string]bool, opts []survey.AskOpt) ([]string, error) {
+	var texts []string
+	var defaults []int
+	for i, item := range items {
+		texts = append(texts, lineText(item))
+		if key := lineKey(item); key != "" && preselected[key] {
+			defaults = append(defaults, i)
+		}
+	}
+	prompt := &survey.MultiSelect{
+		Message:  message,
+		Options:  texts,
+		PageSize: 15,
+		Default:  defaults,
+		Filter: func(filter, value string, index int) bool {
+			return strings.Contains(strings.ToLower(ansiStripper.ReplaceAllString(value, "")), strings.ToLower(filter))
+		},
+	}
+	// The answers carry the index, as two items may show the same text
+	var answers []core.OptionAnswer
+	if err := survey.AskOne(prompt, &answers, opts...); err != nil {
+		if err == terminal.InterruptErr {
+			return nil, errFzfCancelled
+		}
+		return nil, err
+	}
+	var selected []string
+	for _, answer := range answers {
+		selected = append(selected, items[answer.Index])
+	}
+	return selected, nil
+}
+
+// splitShellWords splits s into words the way a shell would for simple cases:
+// whitespace separates words, single and double quotes group them and a backslash escapes the next character
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+)
+
+// testLocalizer returns a localizer for lang with the messages of the locales directory
+func testLocalizer(t *testing.T, lang string) *i18n.Localizer {
+	t.Helper()
+	bundle := i18n.NewBundle(language.English)
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+	for _, path := range []string{"locales/en.json", "locales/ja.json"} {
+		if _, err := bundle.LoadMessageFileFS(localeFS, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return i18n.NewLocalizer(bundle, lang)
+}
+
+func TestFzfHeaderKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		lang  string
+		bound []string
+		want  string
+	}{
+		{"all keys", "en", []string{"tab", "enter", "ctrl-a", "ctrl-d", "ctrl-f", "ctrl-o"},
+			"Tab: select, ctrl-a: all, ctrl-d: none, Enter: confirm, ctrl-f: force, ctrl-o: preview"},
+		{"without preview and force", "en", []string{"tab", "enter", "ctrl-a", "ctrl-d"},
+			"Tab: select, ctrl-a: all, ctrl-d: none, Enter: confirm"},
+		{"nothing bound", "en", nil, ""},
+		{"japanese", "ja", []string{"tab", "enter", "ctrl-f"}, "Tab: 選択, Enter: 確定, ctrl-f: 強制"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound := make(map[string]bool)
+			for _, key := range tt.bound {
+				bound[key] = true
+			}
+			if got := fzfHeaderKeys(testLocalizer(t, tt.lang), bound); got != tt.want {
+				t.Errorf("fzfHeaderKeys(%v) = %q, want %q", tt.bound, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFzfReboundKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"equals form", []string{"--bind=ctrl-d:page-down"}, []string{"ctrl-d"}},
+		{"separate word", []string{"--bind", "ctrl-a:toggle-all"}, []string{"ctrl-a"}},
+		{"several bindings", []string{"--bind=ctrl-d:page-down,ctrl-u:page-up"}, []string{"ctrl-d", "ctrl-u"}},
+		{"several options", []string{"--height=40%", "--bind", "ctrl-f:page-down", "--bind=Tab:down"}, []string{"ctrl-f", "tab"}},
+		{"chained actions", []string{"--bind=enter:accept+abort"}, []string{"enter"}},
+		{"no binding", []string{"--height=40%", "--layout=reverse"}, nil},
+		{"bind without a spec", []string{"--bind"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fzfReboundKeys(tt.args)
+			if len(got) != len(tt.want) {
+				t.Fatalf("fzfReboundKeys(%q) = %v, want %q", tt.args, got, tt.want)
+			}
+			for _, key := range tt.want {
+				if !got[key] {
+					t.Errorf("fzfReboundKeys(%q) = %v, want %q", tt.args, got, tt.want)
+				}
+			}
+		})
+	}
+}
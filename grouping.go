@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Prefix of the header lines inserted by groupItemsByPrefix. Like the status divider they have
+// no key, so selecting them does nothing.
+const groupHeaderPrefix = "──"
+
+// branchPrefix returns the first path segment of a branch name including the slash, e.g. "feature/"
+func branchPrefix(branch string) string {
+	if i := strings.Index(branch, "/"); i >= 0 {
+		return branch[:i+1]
+	}
+	return ""
+}
+
+// groupItemsByPrefix orders the branches by their first path segment and puts a header line in
+// front of every group with more than one branch. items maps each branch to its fzf line.
+func groupItemsByPrefix(branches []string, items map[string]string) []string {
+	sorted := append([]string{}, branches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return branchPrefix(sorted[i]) < branchPrefix(sorted[j])
+	})
+
+	var lines []string
+	for start := 0; start < len(sorted); {
+		prefix := branchPrefix(sorted[start])
+		end := start + 1
+		for end < len(sorted) && branchPrefix(sorted[end]) == prefix {
+			end++
+		}
+		if prefix != "" && end-start > 1 {
+			header := fmt.Sprintf("%s %s (%d) %s", groupHeaderPrefix, prefix, end-start, groupHeaderPrefix)
+			lines = append(lines, keyedLine("", ColorDim+header+ColorReset))
+		}
+		for _, branch := range sorted[start:end] {
+			lines = append(lines, items[branch])
+		}
+		start = end
+	}
+	return lines
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// runHook runs the shell command for a branch with GDB_BRANCH, GDB_SHA, GDB_MERGED and GDB_UPSTREAM set.
+// The output of the command is only shown when verbose.
+func runHook(command string, info BranchInfo, merged, verbose bool) error {
+	cmd := newCommand("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"GDB_BRANCH="+info.Name,
+		"GDB_SHA="+info.Hash,
+		"GDB_MERGED="+strconv.FormatBool(merged),
+		"GDB_UPSTREAM="+info.Upstream,
+	)
+	cmd.Stdout, cmd.Stderr = io.Discard, io.Discard
+	if verbose {
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	}
+	return cmd.Run()
+}
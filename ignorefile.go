@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Name of the ignore file looked up in the repository root
+const ignoreFileName = ".git-delete-branch-ignore"
+
+// getIgnoreFilePaths returns the ignore files of the current repository, whether they exist or not
+func getIgnoreFilePaths() []string {
+	var paths []string
+	if output, err := newCommand("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		paths = append(paths, filepath.Join(strings.TrimSpace(string(output)), ignoreFileName))
+	}
+	if output, err := newCommand("git", "rev-parse", "--git-common-dir").Output(); err == nil {
+		paths = append(paths, filepath.Join(strings.TrimSpace(string(output)), "info", "delete-branch-ignore"))
+	}
+	return paths
+}
+
+// readIgnorePatterns reads one glob pattern per line from the given files.
+// Blank lines and lines starting with # are skipped, and missing files are ignored.
+func readIgnorePatterns(paths []string) ([]string, error) {
+	var patterns []string
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			patterns = append(patterns, line)
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return patterns, nil
+}
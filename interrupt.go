@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+)
+
+// exitInterrupted is the exit status of a run stopped by Ctrl+C, as shells report for SIGINT
+const exitInterrupted = 130
+
+// watchInterrupts keeps Ctrl+C from killing the process for the rest of the run
+// and records that it was pressed instead
+func watchInterrupts() *atomic.Bool {
+	interrupted := new(atomic.Bool)
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		for range interrupts {
+			interrupted.Store(true)
+		}
+	}()
+	return interrupted
+}
+
+// uninterruptibleCommand returns a git command that Ctrl+C in the terminal does not reach,
+// so a deletion that was already started is finished rather than killed half-way
+func uninterruptibleCommand(args ...string) *loggedCmd {
+	cmd := newCommand("git", args...)
+	detachFromTerminalSignals(cmd.Cmd)
+	return cmd
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// detachFromTerminalSignals does nothing where process groups are not available
+func detachFromTerminalSignals(cmd *exec.Cmd) {}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// journalFileName is the append-only record of deletions, kept in the git common directory
+const journalFileName = "git-delete-branch.log"
+
+// journalEntry is one line of the journal
+type journalEntry struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	Branch  string    `json:"branch"`
+	Hash    string    `json:"hash"`
+	Merged  bool      `json:"merged"`
+	Forced  bool      `json:"forced"`
+	// Remote is set for the deletion of the upstream branch on that remote
+	Remote  string `json:"remote,omitempty"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// journal appends entries as JSON lines
+type journal struct {
+	file *os.File
+}
+
+// openJournal opens the journal of the current repository for appending
+func openJournal() (*journal, error) {
+	output, err := newCommand("git", "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(strings.TrimSpace(string(output)), journalFileName)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{file: file}, nil
+}
+
+// Write appends the entry. A nil journal discards it.
+func (j *journal) Write(entry journalEntry) error {
+	if j == nil {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = j.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the journal file. A nil journal is a no-op.
+func (j *journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	return j.file.Close()
+}
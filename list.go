@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Values of --list-format
+var listFormats = []string{"plain", "tsv"}
+
+// listEntry is a candidate printed by --list
+type listEntry struct {
+	Name string
+	// Untranslated merge status, e.g. "merged", "squash-merged" or "unmerged"
+	Status string
+	// Committer date of the tip
+	Date time.Time
+	Safe bool
+}
+
+// printBranchList prints the entries for --list, each ended by terminator. The plain format is
+// the branch names, colored by merge status on a terminal. The tsv format is the name, status
+// and date separated by tabs and never colored.
+func printBranchList(w io.Writer, entries []listEntry, format, terminator string) {
+	colored := colorsEnabled && isTerminal(os.Stdout)
+	for _, entry := range entries {
+		switch format {
+		case "tsv":
+			fmt.Fprintf(w, "%s\t%s\t%s%s", entry.Name, entry.Status, entry.Date.Format(time.RFC3339), terminator)
+		default:
+			if colored {
+				color := ColorUnmerged
+				if entry.Safe {
+					color = ColorMerged
+				}
+				fmt.Fprintf(w, "%s%s%s%s", color, entry.Name, ColorReset, terminator)
+			} else {
+				fmt.Fprint(w, entry.Name+terminator)
+			}
+		}
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -19,13 +26,59 @@ import (
 //go:embed locales/*.json
 var localeFS embed.FS
 
-// ANSI escape code for colors
-const (
-	ColorGreen = "\033[32m"
-	ColorRed   = "\033[31m"
-	ColorReset = "\033[0m"
+// ANSI escape codes of the colors by role. applyTheme sets them and disableColors empties them.
+var (
+	ColorMerged   = "\033[32m"
+	ColorUnmerged = "\033[31m"
+	ColorWarning  = "\033[31m"
+	ColorDim      = "\033[2m"
+	ColorAccent   = "\033[35m"
+	ColorRemote   = "\033[36m"
+	ColorGone     = "\033[33m"
+	ColorReset    = "\033[0m"
 )
 
+// colorsEnabled is false after disableColors
+var colorsEnabled = true
+
+// disableColors makes all output plain text. The indicators such as "(merged)" still tell the
+// branches apart without the colors.
+func disableColors() {
+	ColorMerged, ColorUnmerged, ColorWarning, ColorDim, ColorAccent, ColorRemote, ColorGone, ColorReset = "", "", "", "", "", "", "", ""
+	colorsEnabled = false
+}
+
+// colorsRequested reports whether colors may be used: not with --no-color, NO_COLOR
+// (https://no-color.org) or a dumb terminal
+func colorsRequested(noColorFlag bool) bool {
+	return !noColorFlag && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb"
+}
+
+// gitColorOption returns the --color option for git output that is shown to the user
+func gitColorOption() string {
+	if colorsEnabled {
+		return "--color=always"
+	}
+	return "--color=never"
+}
+
+// fzfColorArgs returns the fzf options that make it interpret the color codes of the lines
+func fzfColorArgs() []string {
+	if colorsEnabled {
+		return []string{"--ansi"}
+	}
+	return []string{"--no-color"}
+}
+
+// Line separating merged from unmerged branches in the fzf list. It has no key, so selecting it does nothing.
+const statusDivider = "────────────────────"
+
+// Columns the author name takes in each fzf line, so that the dates after it line up
+const authorColumnWidth = 16
+
+// Branches that are never offered for deletion unless --no-protect is given
+var defaultProtectedBranches = []string{"main", "master", "develop"}
+
 // Regex to remove ANSI color codes
 var ansiStripper = regexp.MustCompile("\033[[0-9;]*m")
 
@@ -35,40 +88,179 @@ type BranchDetail struct {
 	Author  string
 	Date    string
 	Message string
+	// Author date of the tip, Date is the same in git's default format
+	AuthorDate time.Time
+}
+
+// stringSliceFlag is a flag.Value that collects every occurrence of a repeatable flag
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// trackingIndicator describes how the branch relates to its upstream, e.g. "[ahead 2, behind 1]",
+// with "[gone]" in red. It is empty for untracked branches and branches in sync with their upstream.
+func trackingIndicator(localizer *i18n.Localizer, info BranchInfo, color string) string {
+	if info.Gone {
+		return ColorWarning + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "GoneIndicator"}) + color
+	}
+	var parts []string
+	if info.Ahead > 0 {
+		parts = append(parts, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "TrackingAhead",
+			TemplateData: map[string]interface{}{"Count": info.Ahead},
+		}))
+	}
+	if info.Behind > 0 {
+		parts = append(parts, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "TrackingBehind",
+			TemplateData: map[string]interface{}{"Count": info.Behind},
+		}))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// shellQuote quotes a string so it can be safely embedded in a shell command line
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// refExists reports whether the given ref resolves to a commit
+func refExists(ref string) bool {
+	cmd := newCommand("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return cmd.Run() == nil
+}
+
+// getRemoteDefaultBranch returns the branch origin/HEAD points to, or "" if it is not set
+func getRemoteDefaultBranch() string {
+	cmd := newCommand("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+}
+
+// getRemoteDefaultRef returns the remote-tracking ref of origin's default branch (e.g. "origin/main"),
+// falling back to origin/main when origin/HEAD is not set, or "" if neither exists
+func getRemoteDefaultRef() string {
+	if defaultBranch := getRemoteDefaultBranch(); defaultBranch != "" {
+		return "origin/" + defaultBranch
+	}
+	if refExists("origin/main") {
+		return "origin/main"
+	}
+	return ""
+}
+
+// getSwitchTarget returns the branch to switch to before deleting the current branch:
+// the local branch of base when given, otherwise origin's default branch or main/master.
+// It returns "" when there is no such branch.
+func getSwitchTarget(base string) string {
+	if base != "" {
+		// git switch creates the local branch of a remote-tracking base such as origin/main
+		return strings.TrimPrefix(base, "origin/")
+	}
+	if defaultBranch := getRemoteDefaultBranch(); defaultBranch != "" {
+		return defaultBranch
+	}
+	for _, branch := range []string{"main", "master"} {
+		if refExists("refs/heads/" + branch) {
+			return branch
+		}
+	}
+	return ""
+}
+
+// getMergeBase returns what the branches are checked against for being merged, and counted
+// ahead and behind of
+func getMergeBase(base string, includeCurrent bool, currentBranch string) string {
+	if base != "" {
+		return base
+	}
+	if includeCurrent && currentBranch != "" {
+		// HEAD is the current branch itself, which would always count as merged. It is deleted
+		// after switching to the target, so that is what git branch -d checks it against.
+		if target := getSwitchTarget(""); target != "" {
+			return target
+		}
+	}
+	return "HEAD"
 }
 
-// cleanBranchName removes color codes and merge indicators from a branch name
-func cleanBranchName(branchName string) string {
-	// First, remove ANSI color codes
-	cleaned := ansiStripper.ReplaceAllString(branchName, "")
-	// Then, remove the merge indicator (e.g., " (merged)" or " (unmerged)")
-	parts := strings.SplitN(cleaned, " (", 2)
-	return strings.TrimSpace(parts[0])
+// isWorkingTreeDirty reports whether tracked files have uncommitted changes
+func isWorkingTreeDirty() bool {
+	output, err := newCommand("git", "status", "--porcelain", "--untracked-files=no").Output()
+	return err != nil || len(strings.TrimSpace(string(output))) > 0
+}
+
+// getProtectedBranches returns the set of branch names that must not be deleted
+func getProtectedBranches() map[string]bool {
+	protected := make(map[string]bool)
+	for _, branch := range defaultProtectedBranches {
+		protected[branch] = true
+	}
+	if defaultBranch := getRemoteDefaultBranch(); defaultBranch != "" {
+		protected[defaultBranch] = true
+	}
+	return protected
+}
+
+// isProtectedBranch reports whether the branch must not be deleted.
+// A remote-tracking branch such as origin/main is as protected as main itself.
+func isProtectedBranch(branch BranchInfo, protected map[string]bool) bool {
+	if protected[branch.Name] {
+		return true
+	}
+	if branch.Remote {
+		_, remoteBranch, _ := strings.Cut(branch.Name, "/")
+		return protected[remoteBranch]
+	}
+	return false
 }
 
 func getBranchDetail(branchName string) (BranchDetail, error) {
-	cleanName := cleanBranchName(branchName)
-	cmd := exec.Command("git", "log", "-1", "--pretty=format:%H%n%an%n%ad%n%s", cleanName)
+	cmd := newCommand("git", "log", "-1", "--pretty=format:%H%n%an%n%ad%n%at%n%s", branchName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return BranchDetail{}, fmt.Errorf("git log failed: %w\n%s", err, string(output))
 	}
 
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	if len(lines) < 4 {
+	if len(lines) < 5 {
 		return BranchDetail{}, fmt.Errorf("unexpected git log output: %s", string(output))
 	}
+	authorDate, err := strconv.ParseInt(lines[3], 10, 64)
+	if err != nil {
+		return BranchDetail{}, fmt.Errorf("unexpected author date in git log output: %s", string(output))
+	}
 
 	return BranchDetail{
-		Name:    cleanName,
-		Hash:    lines[0],
-		Author:  lines[1],
-		Date:    lines[2],
-		Message: lines[3],
+		Name:       branchName,
+		Hash:       lines[0],
+		Author:     lines[1],
+		Date:       lines[2],
+		AuthorDate: time.Unix(authorDate, 0),
+		Message:    lines[4],
 	}, nil
 }
 
 func main() {
+	os.Exit(run())
+}
+
+// run is the whole command. It returns the exit status instead of calling os.Exit, so the
+// deferred cleanups such as removing the temp files of fzf run on every way out.
+func run() int {
 	bundle := i18n.NewBundle(language.English)
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 	bundle.LoadMessageFileFS(localeFS, "locales/en.json")
@@ -79,11 +271,133 @@ func main() {
 	helpFlag := flag.Bool("h", false, "Show help")
 	flag.BoolVar(helpFlag, "help", false, "Show help")
 
+	yesFlag := flag.Bool("y", false, "Delete the selected branches without asking for confirmation")
+	flag.BoolVar(yesFlag, "yes", false, "Delete the selected branches without asking for confirmation")
+	remoteFlag := flag.Bool("remote", false, "Also delete the upstream branch on its remote after deleting a local branch")
+	pruneTrackingFlag := flag.Bool("prune-tracking", false, "Delete the remote-tracking refs of deleted branches that no longer exist on the remote")
+	keepConfigFlag := flag.Bool("keep-config", false, "Keep the branch.<name> config sections of deleted branches")
+	noBackupFlag := flag.Bool("no-backup", false, "Do not keep backup refs of the deleted branches")
+	noJournalFlag := flag.Bool("no-journal", false, "Do not record the deletions in the journal")
+	remoteJobsFlag := flag.Int("remote-jobs", 4, "Number of remotes pushed to at the same time with --remote")
+	archiveFlag := flag.Bool("archive", false, "Keep each deleted branch as an archive/<branch> tag")
+	trashFlag := flag.Bool("trash", false, "Move the selected branches to refs/trash/ instead of deleting them")
+	dropStashesFlag := flag.Bool("drop-stashes", false, "Drop the stashes made on the deleted branches, after asking")
+	showTrashFlag := flag.Bool("show-trash", false, "List the trashed branches and restore the selected ones")
+	failFastFlag := flag.Bool("fail-fast", false, "Stop deleting the remaining branches after the first failure")
+	atomicFlag := flag.Bool("atomic", false, "Restore the deleted branches when any other selected branch fails to delete")
+	confirmThresholdFlag := flag.Int("confirm-threshold", defaultConfirmThreshold, "Ask to type the branch count to confirm when more branches are selected, 0 never asks")
+	confirmEachFlag := flag.Bool("confirm-each", false, "Ask for confirmation of each selected branch separately")
+	preDeleteCmdFlag := flag.String("pre-delete-cmd", "", "Shell command run before deleting each branch, a non-zero exit skips the branch")
+	postDeleteCmdFlag := flag.String("post-delete-cmd", "", "Shell command run after each branch was deleted")
+	verboseFlag := flag.Bool("verbose", false, "Log every command run to stderr and show the output of the delete hooks")
+	quietFlag := flag.Bool("quiet", false, "Only print errors and the summary, without notes and per-branch success output")
+	listFlag := flag.Bool("list", false, "Print the candidates, one per line, instead of selecting and deleting them")
+	listFormatFlag := flag.String("list-format", "plain", "Format of --list: plain (names) or tsv (name, status and date)")
+	nulFlag := flag.Bool("z", false, "End the --list lines with NUL instead of a newline")
+	dryRunFlag := flag.Bool("dry-run", false, "Print the git commands that would delete the selected branches without running them")
+	forceFlag := flag.Bool("D", false, "Force delete the selected branches with git branch -D")
+	allowUnpushedFlag := flag.Bool("allow-unpushed", false, "Allow force deleting branches whose tip commit does not exist on any remote")
+	flag.BoolVar(forceFlag, "force", false, "Force delete the selected branches with git branch -D")
+	mergedFlag := flag.Bool("merged", false, "Only list branches merged into HEAD")
+	unmergedFlag := flag.Bool("unmerged", false, "Only list branches not merged into HEAD")
+	var excludeFlag stringSliceFlag
+	flag.Var(&excludeFlag, "exclude", "Glob pattern of branches to exclude (repeatable)")
+	regexFlag := flag.Bool("regex", false, "Treat pattern arguments and --exclude values as regular expressions")
+	baseFlag := flag.String("base", "", "Compute merged status against this ref instead of HEAD")
+	remotesFlag := flag.Bool("r", false, "List remote-tracking branches instead of local branches")
+	allFlag := flag.Bool("a", false, "List both local and remote-tracking branches")
+	goneFlag := flag.Bool("gone", false, "Only list branches whose upstream branch was deleted")
+	noUpstreamFlag := flag.Bool("no-upstream", false, "Only list local branches without an upstream branch")
+	noAheadFlag := flag.Bool("no-ahead", false, "Only list branches without commits that are not on the base")
+	pushedFlag := flag.Bool("pushed", false, "Only list branches whose tip commit exists on a remote")
+	unpushedFlag := flag.Bool("unpushed", false, "Only list branches whose tip commit does not exist on any remote")
+	containedFlag := flag.Bool("contained", false, "Mark unmerged branches whose commits are all contained in another local branch")
+	duplicatesFlag := flag.Bool("duplicates", false, "Only list branches pointing at the same commit as another local branch")
+	olderThanFlag := flag.String("older-than", "", "Only list branches whose last commit is older than the duration (e.g. 90d, 12w, 36h)")
+	createdBeforeFlag := flag.String("created-before", "", "Only list branches created before the date (YYYY-MM-DD) or duration ago (e.g. 30d)")
+	strictFlag := flag.Bool("strict", false, "Exclude branches whose creation date is unknown from --created-before")
+	divergedBeforeFlag := flag.String("diverged-before", "", "Only list branches that diverged from the base longer ago than the duration (e.g. 180d)")
+	var pathFlag stringSliceFlag
+	flag.Var(&pathFlag, "path", "Only list branches whose commits ahead of the base touch the path (repeatable)")
+	authorFlag := flag.String("author", "", "Only list branches whose last commit author name or email matches the regular expression")
+	mineFlag := flag.Bool("mine", false, "Only list branches whose last commit was authored with your user.email")
+	includeCurrentFlag := flag.Bool("include-current", false, "Also list the current branch, switching to the base branch before deleting it")
+	includeWorktreesFlag := flag.Bool("include-worktrees", false, "Also list branches checked out in other worktrees")
+	groupByStatusFlag := flag.Bool("group-by-status", true, "List merged branches ahead of unmerged ones")
+	containsFlag := flag.String("contains", "", "Only list branches that contain the commit")
+	noContainsFlag := flag.String("no-contains", "", "Only list branches that do not contain the commit")
+	keepRecentFlag := flag.Int("keep-recent", 0, "Never list the N most recently committed branches")
+	maxCountFlag := flag.Int("max-count", 0, "Only list the first N branches after sorting and filtering")
+	groupByPrefixFlag := flag.Bool("group-by-prefix", false, "Group branches by their first path segment (e.g. feature/)")
+	sortFlag := flag.String("sort", "refname", "Sort branches by committerdate, authordate or refname (prefix with - for descending)")
+	previewWindowFlag := flag.String("preview-window", "", "fzf --preview-window spec of the preview pane (e.g. right:60%, down:40%)")
+	previewLimitFlag := flag.Int("preview-limit", defaultPreviewLimit, "Number of commits listed in the preview, 0 lists all")
+	previewFormatFlag := flag.String("preview-format", "oneline", "Log format of the preview: oneline, full or a git log --format string")
+	noPreviewFlag := flag.Bool("no-preview", false, "Do not show the preview pane in fzf")
+	var fzfArgFlag stringSliceFlag
+	flag.Var(&fzfArgFlag, "fzf-arg", "Extra argument appended to the fzf command line (repeatable)")
+	showAheadBehindFlag := flag.Bool("show-ahead-behind", false, "Show how many commits each branch is ahead of and behind the base")
+	themeFlag := flag.String("theme", "default", "Color theme: default, light or mono")
+	noColorFlag := flag.Bool("no-color", false, "Do not use colors, also when NO_COLOR is unset")
+	noTruncateFlag := flag.Bool("no-truncate", false, "Do not cut the confirmation table to the terminal width")
+	noAuthorsFlag := flag.Bool("no-authors", false, "Do not show the author of the last commit of each branch")
+	dateFlag := flag.String("date", "default", "Date format: default, relative, iso or short")
+	noDatesFlag := flag.Bool("no-dates", false, "Do not show how long ago the last commit of each branch was")
+	tuiFlag := flag.Bool("tui", false, "Pick the branches from a built-in full-screen list instead of fzf")
+	noFzfFlag := flag.Bool("no-fzf", false, "Pick the branches from a built-in list instead of fzf")
+	queryFlag := flag.String("query", "", "Start the fzf selection with the given query")
+	stdinFlag := flag.Bool("stdin", false, "Read newline-separated candidate branch names from stdin")
+	stdin0Flag := flag.Bool("stdin0", false, "Read NUL-separated candidate branch names from stdin")
+	withDescriptionOnlyFlag := flag.Bool("with-description-only", false, "Only list branches that have a branch description")
+	noIgnoreFileFlag := flag.Bool("no-ignore-file", false, "Do not read the .git-delete-branch-ignore file")
+	detectRebaseMergesFlag := flag.Bool("detect-rebase-merges", false, "Detect branches whose commits were rebased onto the base")
+	fetchFlag := flag.Bool("fetch", false, "Run git fetch --prune before listing branches")
+	mergedIntoRemoteFlag := flag.Bool("merged-into-remote", false, "Compute merged status against origin's default branch")
+	cleanupFlag := flag.Bool("cleanup", false, "Delete every merged branch and every branch whose upstream is gone, without fzf")
+	var protectFlag stringSliceFlag
+	flag.Var(&protectFlag, "protect", "Glob pattern of branches that are never offered for deletion (repeatable)")
+	noProtectFlag := flag.Bool("no-protect", false, "Allow deleting main, master, develop and the remote default branch")
+
 	// Internal flag for fzf preview
 	getLogFlag := flag.String("get-log", "", "Internal flag to get log for a branch")
+	// Internal flags for the preview mode the fzf ctrl-o binding switches
+	previewModeFileFlag := flag.String("preview-mode-file", "", "Internal flag with the file holding the preview mode")
+	cyclePreviewModeFlag := flag.String("cycle-preview-mode", "", "Internal flag to switch the preview mode in the given file to the next one")
+	// Internal flags for the fzf ctrl-f binding
+	toggleForceFlag := flag.String("toggle-force", "", "Internal flag to toggle force deletion of a branch in the fzf items file")
+	forceFileFlag := flag.String("force-file", "", "Internal flag with the file holding the branches toggled for force deletion")
 
 	flag.Parse()
 
+	// Flags given on the command line take precedence over the environment and the git config
+	unknownConfigKeys, invalidDefaults := applyDefaults(flag.CommandLine)
+	for _, invalid := range invalidDefaults {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid default %s\n", invalid)
+	}
+	// --list writes the candidates to stdout for scripts, so warnings and errors go to stderr then
+	diagnostics := io.Writer(os.Stdout)
+	if *listFlag || *nulFlag {
+		diagnostics = os.Stderr
+	}
+	verboseCommands = *verboseFlag
+	if !colorsRequested(*noColorFlag) {
+		disableColors()
+	}
+	if *verboseFlag {
+		for _, key := range unknownConfigKeys {
+			fmt.Fprintf(os.Stderr, "Note: ignoring unknown git config key %s\n", key)
+		}
+	}
+
+	// Positional arguments are glob (or, with --regex, regular expression) patterns used to
+	// pre-filter the candidates.
+	// When none of them contains a glob metacharacter they are the branches to delete.
+	patterns := flag.Args()
+	var explicitBranches []string
+	if len(patterns) > 0 && !*regexFlag && !slices.ContainsFunc(patterns, hasGlobMeta) {
+		explicitBranches, patterns = patterns, nil
+	}
+
 	var lang string
 	if *langFlag != "" {
 		lang = *langFlag
@@ -97,221 +411,2208 @@ func main() {
 
 	localizer := i18n.NewLocalizer(bundle, lang)
 
+	if !isValidDateMode(*dateFlag) {
+		fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "InvalidDateMode",
+			TemplateData: map[string]interface{}{"Value": *dateFlag},
+		}))
+		return 1
+	}
+	if _, ok := themePresets[*themeFlag]; !ok {
+		fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "InvalidTheme",
+			TemplateData: map[string]interface{}{"Value": *themeFlag},
+		}))
+		return 1
+	}
+	if colorsEnabled {
+		for _, invalid := range applyTheme(*themeFlag) {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid color %s\n", invalid)
+		}
+	}
+
+	// Handle internal fzf ctrl-o request
+	if *cyclePreviewModeFlag != "" {
+		if err := cyclePreviewMode(*cyclePreviewModeFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error switching the preview mode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Handle internal fzf ctrl-f request, the branch to toggle is the positional argument
+	if *toggleForceFlag != "" {
+		if err := toggleForceInFile(*toggleForceFlag, *forceFileFlag, strings.Join(flag.Args(), " ")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error toggling force deletion: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// "undo" is a subcommand rather than a branch to delete
+	if flag.Arg(0) == "undo" {
+		runUndo(localizer, flag.Args()[1:])
+	}
+	if flag.Arg(0) == "empty-trash" {
+		runEmptyTrash(localizer, flag.Args()[1:])
+	}
+
 	// Handle internal fzf preview request
 	if *getLogFlag != "" {
-		cleanName := cleanBranchName(*getLogFlag)
-		cmd := exec.Command("git", "log", "--color=always", cleanName)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		err := cmd.Run()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting log for %s: %v\n", cleanName, err)
-			os.Exit(1)
-		}
-		os.Exit(0)
+		runPreview(localizer, *getLogFlag, *baseFlag, *previewFormatFlag, *previewLimitFlag, readPreviewMode(*previewModeFileFlag))
 	}
 
 	if *helpFlag {
 		usage, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "HelpUsage"})
 		description, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "HelpDescription"})
-		help, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "HelpFlag"})
-		langHelp, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "HelpLangFlag"})
+		fmt.Printf("%s\n\n%s\n\nOptions:\n", usage, description)
+
+		options := []struct {
+			Flag      string
+			MessageID string
+		}{
+			{"-h, --help", "HelpFlag"},
+			{"-lang string", "HelpLangFlag"},
+			{"-r", "HelpRemotesFlag"},
+			{"-a", "HelpAllFlag"},
+			{"-D, --force", "HelpForceFlag"},
+			{"--allow-unpushed", "HelpAllowUnpushedFlag"},
+			{"--remote", "HelpRemoteFlag"},
+			{"--remote-jobs n", "HelpRemoteJobsFlag"},
+			{"--archive", "HelpArchiveFlag"},
+			{"--trash", "HelpTrashFlag"},
+			{"--show-trash", "HelpShowTrashFlag"},
+			{"empty-trash", "HelpEmptyTrashCommand"},
+			{"empty-trash --older-than", "HelpEmptyTrashOlderThanFlag"},
+			{"--prune-tracking", "HelpPruneTrackingFlag"},
+			{"--drop-stashes", "HelpDropStashesFlag"},
+			{"--no-backup", "HelpNoBackupFlag"},
+			{"--keep-config", "HelpKeepConfigFlag"},
+			{"--no-journal", "HelpNoJournalFlag"},
+			{"undo", "HelpUndoCommand"},
+			{"undo --list", "HelpUndoListFlag"},
+			{"undo --session id", "HelpUndoSessionFlag"},
+			{"--dry-run", "HelpDryRunFlag"},
+			{"--list", "HelpListFlag"},
+			{"--list-format format", "HelpListFormatFlag"},
+			{"-z", "HelpNulFlag"},
+			{"-y, --yes", "HelpYesFlag"},
+			{"--confirm-each", "HelpConfirmEachFlag"},
+			{"--confirm-threshold n", "HelpConfirmThresholdFlag"},
+			{"--atomic", "HelpAtomicFlag"},
+			{"--fail-fast", "HelpFailFastFlag"},
+			{"--quiet", "HelpQuietFlag"},
+			{"--verbose", "HelpVerboseFlag"},
+			{"--pre-delete-cmd cmd", "HelpPreDeleteCmdFlag"},
+			{"--post-delete-cmd cmd", "HelpPostDeleteCmdFlag"},
+			{"--merged", "HelpMergedFlag"},
+			{"--unmerged", "HelpUnmergedFlag"},
+			{"--exclude pattern", "HelpExcludeFlag"},
+			{"--regex", "HelpRegexFlag"},
+			{"--protect pattern", "HelpProtectFlag"},
+			{"--no-protect", "HelpNoProtectFlag"},
+			{"--cleanup", "HelpCleanupFlag"},
+			{"--include-worktrees", "HelpIncludeWorktreesFlag"},
+			{"--include-current", "HelpIncludeCurrentFlag"},
+			{"--no-ignore-file", "HelpNoIgnoreFileFlag"},
+			{"--with-description-only", "HelpWithDescriptionOnlyFlag"},
+			{"--query string", "HelpQueryFlag"},
+			{"--no-fzf", "HelpNoFzfFlag"},
+			{"--tui", "HelpTUIFlag"},
+			{"--no-dates", "HelpNoDatesFlag"},
+			{"--date mode", "HelpDateFlag"},
+			{"--no-authors", "HelpNoAuthorsFlag"},
+			{"--no-truncate", "HelpNoTruncateFlag"},
+			{"--no-color", "HelpNoColorFlag"},
+			{"--theme name", "HelpThemeFlag"},
+			{"--show-ahead-behind", "HelpShowAheadBehindFlag"},
+			{"--fzf-arg arg", "HelpFzfArgFlag"},
+			{"--preview-window spec", "HelpPreviewWindowFlag"},
+			{"--no-preview", "HelpNoPreviewFlag"},
+			{"--preview-limit n", "HelpPreviewLimitFlag"},
+			{"--preview-format fmt", "HelpPreviewFormatFlag"},
+			{"--stdin", "HelpStdinFlag"},
+			{"--stdin0", "HelpStdin0Flag"},
+			{"--contains commit", "HelpContainsFlag"},
+			{"--no-contains commit", "HelpNoContainsFlag"},
+			{"--sort key", "HelpSortFlag"},
+			{"--max-count n", "HelpMaxCountFlag"},
+			{"--keep-recent n", "HelpKeepRecentFlag"},
+			{"--group-by-status=false", "HelpGroupByStatusFlag"},
+			{"--group-by-prefix", "HelpGroupByPrefixFlag"},
+			{"--base ref", "HelpBaseFlag"},
+			{"--merged-into-remote", "HelpMergedIntoRemoteFlag"},
+			{"--fetch", "HelpFetchFlag"},
+			{"--detect-rebase-merges", "HelpDetectRebaseMergesFlag"},
+			{"--gone", "HelpGoneFlag"},
+			{"--no-upstream", "HelpNoUpstreamFlag"},
+			{"--no-ahead", "HelpNoAheadFlag"},
+			{"--duplicates", "HelpDuplicatesFlag"},
+			{"--contained", "HelpContainedFlag"},
+			{"--pushed", "HelpPushedFlag"},
+			{"--unpushed", "HelpUnpushedFlag"},
+			{"--older-than duration", "HelpOlderThanFlag"},
+			{"--created-before date", "HelpCreatedBeforeFlag"},
+			{"--strict", "HelpStrictFlag"},
+			{"--diverged-before duration", "HelpDivergedBeforeFlag"},
+			{"--path dir", "HelpPathFlag"},
+			{"--author pattern", "HelpAuthorFlag"},
+			{"--mine", "HelpMineFlag"},
+		}
+		for _, o := range options {
+			text, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: o.MessageID})
+			fmt.Printf("  %-24s %s\n", o.Flag, text)
+		}
+		return 0
+	}
 
-		fmt.Printf("%s\n\n%s\n\nOptions:\n  -h, --help    %s\n  -lang string  %s\n", usage, description, help, langHelp)
-		os.Exit(0)
+	for _, conflict := range []struct {
+		First, Second string
+		Set           bool
+	}{
+		{"--merged", "--unmerged", *mergedFlag && *unmergedFlag},
+		{"--pushed", "--unpushed", *pushedFlag && *unpushedFlag},
+		{"--base", "--merged-into-remote", *baseFlag != "" && *mergedIntoRemoteFlag},
+		{"--yes", "--confirm-each", *yesFlag && *confirmEachFlag},
+		{"--quiet", "--verbose", *quietFlag && *verboseFlag},
+		{"--list", "--cleanup", *listFlag && *cleanupFlag},
+		{"--tui", "--no-fzf", *tuiFlag && *noFzfFlag},
+		{"--list", "--dry-run", *listFlag && *dryRunFlag},
+	} {
+		if conflict.Set {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "ConflictingFlags",
+				TemplateData: map[string]interface{}{"First": conflict.First, "Second": conflict.Second},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
+		}
 	}
 
-	// Check if fzf is installed
-	if _, err := exec.LookPath("fzf"); err != nil {
-		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "FzfNotFound"}))
-		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "InstallFzf"}))
-		os.Exit(1)
+	if !slices.Contains(listFormats, *listFormatFlag) {
+		fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "InvalidListFormat",
+			TemplateData: map[string]interface{}{"Value": *listFormatFlag},
+		}))
+		return 1
+	}
+	// The notes would end up in the list
+	if *listFlag {
+		*quietFlag = true
 	}
 
-	// Get current branch
-	currentBranchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	currentBranchOutput, err := currentBranchCmd.CombinedOutput()
-	if err != nil {
+	// Any spec fzf understands is passed through, only an empty one is certainly a mistake
+	emptyPreviewWindow := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "preview-window" && strings.TrimSpace(f.Value.String()) == "" {
+			emptyPreviewWindow = true
+		}
+	})
+	if emptyPreviewWindow {
+		fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "EmptyFlagValue",
+			TemplateData: map[string]interface{}{"Flag": "--preview-window"},
+		}))
+		return 1
+	}
+
+	if *showTrashFlag {
+		runShowTrash(localizer, *yesFlag)
+	}
+
+	includeMatcher, err := newBranchMatcher(patterns, *regexFlag)
+	var excludeMatcher, protectMatcher *branchMatcher
+	if err == nil {
+		excludeMatcher, err = newBranchMatcher(excludeFlag, *regexFlag)
+	}
+	if err == nil {
+		// Protection is configured once, so it does not follow --regex
+		protectMatcher, err = newBranchMatcher(protectFlag, false)
+	}
+	var patternErr *patternError
+	if errors.As(err, &patternErr) {
 		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
-			MessageID: "ErrorGettingCurrentBranch",
-			TemplateData: map[string]interface{}{"Error": err},
+			MessageID:    "InvalidPattern",
+			TemplateData: map[string]interface{}{"Pattern": patternErr.Pattern, "Error": patternErr.Err},
 		})
-		fmt.Println(msg)
-		os.Exit(1)
+		fmt.Fprintln(diagnostics, msg)
+		return 1
 	}
-	currentBranch := strings.TrimSpace(string(currentBranchOutput))
 
-	// Get all local branches
-	cmd := exec.Command("git", "branch")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	if *baseFlag != "" && !refExists(*baseFlag) {
 		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
-			MessageID: "ErrorRunningGitBranch",
-			TemplateData: map[string]interface{}{"Error": err},
+			MessageID:    "InvalidBaseRef",
+			TemplateData: map[string]interface{}{"Base": *baseFlag},
 		})
-		fmt.Println(msg)
-		os.Exit(1)
+		fmt.Fprintln(diagnostics, msg)
+		return 1
 	}
 
-	allBranches := strings.Split(string(output), "\n")
+	base := *baseFlag
+	if *mergedIntoRemoteFlag {
+		base = getRemoteDefaultRef()
+		if base == "" {
+			fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RemoteDefaultBranchNotFound"}))
+		}
+	}
 
-	// Get merged branches
-	mergedCmd := exec.Command("git", "branch", "--merged")
-	mergedOutput, err := mergedCmd.CombinedOutput()
-	if err != nil {
-		// Log error but continue, as this is not critical
-		fmt.Fprintf(os.Stderr, "Warning: Could not get merged branches: %v\n", err)
+	var olderThan time.Duration
+	if *olderThanFlag != "" {
+		var err error
+		olderThan, err = parseAgeDuration(*olderThanFlag)
+		if err != nil {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "InvalidDuration",
+				TemplateData: map[string]interface{}{"Flag": "--older-than", "Value": *olderThanFlag},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
+		}
 	}
-	mergedBranchesMap := make(map[string]bool)
-	for _, branch := range strings.Split(string(mergedOutput), "\n") {
-		mergedBranchesMap[strings.TrimSpace(strings.TrimPrefix(branch, "* "))] = true
+
+	var createdBefore time.Time
+	if *createdBeforeFlag != "" {
+		var err error
+		createdBefore, err = parseTimeThreshold(*createdBeforeFlag)
+		if err != nil {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "InvalidDuration",
+				TemplateData: map[string]interface{}{"Flag": "--created-before", "Value": *createdBeforeFlag},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
+		}
 	}
 
-	var fzfItems []string
-	for _, branch := range allBranches {
-		branch = strings.TrimSpace(strings.TrimPrefix(branch, "* "))
-		if branch != "" && branch != currentBranch {
-			indicator := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UnmergedIndicator"})
-			color := ColorRed
-			if mergedBranchesMap[branch] {
-				indicator = localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "MergedIndicator"})
-				color = ColorGreen
-			}
-			fzfItems = append(fzfItems, fmt.Sprintf("%s%s %s%s", color, branch, indicator, ColorReset))
+	var divergedBefore time.Duration
+	if *divergedBeforeFlag != "" {
+		var err error
+		divergedBefore, err = parseAgeDuration(*divergedBeforeFlag)
+		if err != nil {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "InvalidDuration",
+				TemplateData: map[string]interface{}{"Flag": "--diverged-before", "Value": *divergedBeforeFlag},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
 		}
 	}
 
-	if len(fzfItems) == 0 {
-		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesToDelete"})
-		fmt.Println(msg)
-		os.Exit(0)
+	var authorPattern *regexp.Regexp
+	if *authorFlag != "" {
+		var err error
+		authorPattern, err = regexp.Compile(*authorFlag)
+		if err != nil {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "InvalidPattern",
+				TemplateData: map[string]interface{}{"Pattern": *authorFlag, "Error": err},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
+		}
+	}
+
+	var userEmail string
+	if *mineFlag {
+		output, err := newCommand("git", "config", "user.email").Output()
+		userEmail = strings.TrimSpace(string(output))
+		if err != nil || userEmail == "" {
+			fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UserEmailNotSet"}))
+			return 1
+		}
+	}
+
+	if !isValidSortKey(*sortFlag) {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "InvalidSortKey",
+			TemplateData: map[string]interface{}{"Key": *sortFlag},
+		})
+		fmt.Fprintln(diagnostics, msg)
+		return 1
 	}
 
-	// Prepare fzf command
-	// Use os.Args[0] to get the path to the current executable
-	executablePath, err := os.Executable()
+	for _, commit := range []struct{ Flag, Value string }{
+		{"--contains", *containsFlag},
+		{"--no-contains", *noContainsFlag},
+	} {
+		if commit.Value != "" && !refExists(commit.Value) {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "InvalidCommit",
+				TemplateData: map[string]interface{}{"Flag": commit.Flag, "Commit": commit.Value},
+			})
+			fmt.Fprintln(diagnostics, msg)
+			return 1
+		}
+	}
+
+	// Named branches and --cleanup already determine the selection
+	skipFzf := len(explicitBranches) > 0 || *cleanupFlag
+
+	// Without fzf the branches are picked from a survey list instead
+	useSurveyPicker := !skipFzf && *noFzfFlag
+	if _, err := exec.LookPath("fzf"); err != nil && !skipFzf && !useSurveyPicker && !*listFlag && !*tuiFlag {
+		useSurveyPicker = true
+		if !*quietFlag {
+			fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "FzfNotFound"}))
+			fmt.Fprintln(diagnostics, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "InstallFzf"}))
+		}
+	}
+
+	// Gone upstreams and remote merge status are only accurate after a prune fetch
+	if *fetchFlag {
+		fetchArgs := []string{"fetch", "--prune", "--quiet", "--all"}
+		if *mergedIntoRemoteFlag {
+			// Only origin's default branch matters
+			fetchArgs = []string{"fetch", "--prune", "--quiet", "origin"}
+		}
+		stopFetchSpinner := startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "Fetching"}))
+		fetchOutput, err := newCommand("git", fetchArgs...).CombinedOutput()
+		stopFetchSpinner()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: git fetch failed, branch information may be stale: %v\n%s", err, fetchOutput)
+		}
+	}
+
+	// Get current branch
+	currentBranchCmd := newCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
+	currentBranchOutput, err := currentBranchCmd.CombinedOutput()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
-		os.Exit(1)
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "ErrorGettingCurrentBranch",
+			TemplateData: map[string]interface{}{"Error": err},
+		})
+		fmt.Fprintln(diagnostics, msg)
+		return 1
+	}
+	currentBranch := strings.TrimSpace(string(currentBranchOutput))
+
+	// Decide which ref namespaces to list
+	refPrefixes := []string{"refs/heads/"}
+	if *remotesFlag {
+		refPrefixes = []string{"refs/remotes/"}
+	}
+	if *allFlag {
+		refPrefixes = []string{"refs/heads/", "refs/remotes/"}
 	}
 
-	fzfCmd := exec.Command("fzf", "--multi", "--ansi", "--preview", fmt.Sprintf("%s -get-log {}", executablePath))
-	fzfCmd.Stderr = os.Stderr // Show fzf errors
+	listOptions := []string{"--sort=" + *sortFlag}
+	if *containsFlag != "" {
+		listOptions = append(listOptions, "--contains="+*containsFlag)
+	}
+	if *noContainsFlag != "" {
+		listOptions = append(listOptions, "--no-contains="+*noContainsFlag)
+	}
 
-	// Pass branches to fzf stdin
-	fzfStdin, err := fzfCmd.StdinPipe()
+	// Get all branches
+	stopSpinner := startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "CollectingBranches"}))
+	allBranches, err := listBranches(refPrefixes, listOptions)
+	stopSpinner()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating stdin pipe for fzf: %v\n", err)
-		os.Exit(1)
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "ErrorRunningGitBranch",
+			TemplateData: map[string]interface{}{"Error": err},
+		})
+		fmt.Fprintln(diagnostics, msg)
+		return 1
 	}
-	go func() {
-		defer fzfStdin.Close()
-		for _, item := range fzfItems {
-			fmt.Fprintln(fzfStdin, item)
+	branchInfos := make(map[string]BranchInfo)
+	for _, branch := range allBranches {
+		branchInfos[branch.Name] = branch
+	}
+
+	protectedBranches := make(map[string]bool)
+	if !*noProtectFlag {
+		protectedBranches = getProtectedBranches()
+		// Remote-tracking branches are protected by their name on the remote
+		for _, info := range allBranches {
+			name := info.Name
+			if info.Remote {
+				_, name, _ = strings.Cut(name, "/")
+			}
+			if protectMatcher.Match(name) {
+				protectedBranches[name] = true
+			}
 		}
-	}()
+	}
 
-	// Capture fzf stdout
-	var fzfStdout bytes.Buffer
-	fzfCmd.Stdout = &fzfStdout
+	// Branches pointing at the same commit as another local branch can be deleted for free
+	duplicateOf := findDuplicateBranches(allBranches, func(name string) bool {
+		return name == currentBranch || protectedBranches[name]
+	})
 
-	// Run fzf
-	err = fzfCmd.Run()
-	if err != nil {
-		// fzf returns non-zero exit code if no selection or cancelled
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 130 {
-			// User cancelled (Ctrl+C or Esc)
-			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
-			os.Exit(0)
+	// Outcome of the run, printed at the very end
+	var summary runSummary
+
+	// Candidates read from stdin or given on the command line replace the discovered branches,
+	// in the order they were given
+	if *stdinFlag || *stdin0Flag || len(explicitBranches) > 0 {
+		names := explicitBranches
+		if *stdinFlag || *stdin0Flag {
+			var sep byte = '\n'
+			if *stdin0Flag {
+				sep = 0
+			}
+			names, err = readBranchNames(os.Stdin, sep)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+				return 1
+			}
+		}
+		allBranches = nil
+		for _, name := range names {
+			info, ok := branchInfos[name]
+			if !ok {
+				msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+					MessageID:    "UnknownBranch",
+					TemplateData: map[string]interface{}{"Branch": name},
+				})
+				fmt.Fprintln(diagnostics, msg)
+				continue
+			}
+			if len(explicitBranches) > 0 && !info.Remote && name == currentBranch && !*includeCurrentFlag {
+				if !*quietFlag {
+					msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+						MessageID:    "SkippingCurrentBranch",
+						TemplateData: map[string]interface{}{"Branch": name},
+					})
+					fmt.Fprintln(diagnostics, msg)
+				}
+				summary.Skipped = append(summary.Skipped, name)
+				continue
+			}
+			if len(explicitBranches) > 0 && isProtectedBranch(info, protectedBranches) {
+				if !*quietFlag {
+					msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+						MessageID:    "SkippingProtectedBranch",
+						TemplateData: map[string]interface{}{"Branch": name},
+					})
+					fmt.Fprintln(diagnostics, msg)
+				}
+				summary.Skipped = append(summary.Skipped, name)
+				continue
+			}
+			allBranches = append(allBranches, info)
 		}
-		fmt.Fprintf(os.Stderr, "Error running fzf: %v\n", err)
-		os.Exit(1)
 	}
 
-	selectedBranchesStr := strings.TrimSpace(fzfStdout.String())
-	if selectedBranchesStr == "" {
-		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesSelected"})
-		fmt.Println(msg)
-		os.Exit(0)
+	mergeBase := getMergeBase(base, *includeCurrentFlag, currentBranch)
+
+	// Get merged branches
+	mergedBranchesMap, err := getMergedBranches(mergeBase, refPrefixes)
+	if err != nil {
+		// Log error but continue, as this is not critical
+		fmt.Fprintf(os.Stderr, "Warning: Could not get merged branches: %v\n", err)
+		mergedBranchesMap = make(map[string]bool)
 	}
 
-	// Clean selected branch names by removing indicators and color codes
-	var branchesToDelete []string
-	for _, selectedItem := range strings.Split(selectedBranchesStr, "\n") {
-		branchesToDelete = append(branchesToDelete, cleanBranchName(selectedItem))
+	// Descriptions set with git branch --edit-description
+	descriptions := getBranchDescriptions()
+
+	// Branches checked out in another worktree cannot be deleted with git branch -d
+	worktreeBranches, err := getWorktreeBranches()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not get worktrees: %v\n", err)
+		worktreeBranches = make(map[string]string)
 	}
+	// The current branch is checked out in this worktree, not in another one
+	delete(worktreeBranches, currentBranch)
+	skippedWorktrees := 0
 
-	// Get details for selected branches
-	var details []BranchDetail
-	for _, branchName := range branchesToDelete {
-		detail, err := getBranchDetail(branchName)
+	// Branches matching the ignore files are hidden just like --exclude patterns
+	ignoreMatcher := &branchMatcher{}
+	if !*noIgnoreFileFlag {
+		ignorePatterns, err := readIgnorePatterns(getIgnoreFilePaths())
+		if err == nil {
+			// Ignore files always contain globs, independently of --regex
+			ignoreMatcher, err = newBranchMatcher(ignorePatterns, false)
+		}
 		if err != nil {
-			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
-				MessageID: "ErrorGettingBranchDetails",
-				TemplateData: map[string]interface{}{"Branch": branchName, "Error": err},
-			})
-			fmt.Println(msg)
+			fmt.Fprintf(os.Stderr, "Warning: Could not read ignore file: %v\n", err)
+			ignoreMatcher = &branchMatcher{}
+		}
+	}
+	ignoredBranches := 0
+
+	var candidates []string
+	for _, info := range allBranches {
+		branch := info.Name
+		if !info.Remote && branch == currentBranch && !*includeCurrentFlag {
 			continue
 		}
-		details = append(details, detail)
+		if isProtectedBranch(info, protectedBranches) {
+			continue
+		}
+		if _, ok := worktreeBranches[branch]; ok && !info.Remote && !*includeWorktreesFlag {
+			skippedWorktrees++
+			continue
+		}
+		if !includeMatcher.Empty() && !includeMatcher.Match(branch) {
+			continue
+		}
+		if excludeMatcher.Match(branch) {
+			continue
+		}
+		if ignoreMatcher.Match(branch) {
+			ignoredBranches++
+			continue
+		}
+		if *withDescriptionOnlyFlag && descriptions[branch] == "" {
+			continue
+		}
+		if *noUpstreamFlag && (info.Remote || info.Upstream != "") {
+			continue
+		}
+		if olderThan > 0 && time.Since(info.CommitterDate) < olderThan {
+			continue
+		}
+		if authorPattern != nil && !authorPattern.MatchString(info.AuthorName) && !authorPattern.MatchString(info.AuthorEmail) {
+			continue
+		}
+		if userEmail != "" && !strings.EqualFold(info.AuthorEmail, userEmail) {
+			continue
+		}
+		candidates = append(candidates, branch)
 	}
 
-	if len(details) == 0 {
-		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesSelected"})
-		fmt.Println(msg)
-		os.Exit(0)
+	if skippedWorktrees > 0 && !*quietFlag {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "SkippedWorktreeBranches",
+			TemplateData: map[string]interface{}{"Count": skippedWorktrees},
+		})
+		fmt.Fprintln(diagnostics, msg)
 	}
 
-	// Display confirmation
-	confirmMsg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "ConfirmDeletion"})
-	fmt.Printf("\n%s\n", confirmMsg)
+	if ignoredBranches > 0 && !*quietFlag {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "HiddenByIgnoreFile",
+			TemplateData: map[string]interface{}{"Count": ignoredBranches},
+		})
+		fmt.Fprintln(diagnostics, msg)
+	}
 
-	branchHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Branch"})
-	hashHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Hash"})
-	authorHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Author"})
-	dateHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Date"})
-	messageHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Message"})
+	// The merge detection below runs git for every candidate
+	stopSpinner = startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "AnalyzingBranches",
+		TemplateData: map[string]interface{}{"Count": len(candidates)},
+	}))
 
-	fmt.Printf("%-20s %-8s %-20s %-25s %s\n", branchHeader, hashHeader, authorHeader, dateHeader, messageHeader)
-	fmt.Println(strings.Repeat("-", 90))
+	// Branches that are not ancestors of the base may still have been squash-merged into it
+	var unmergedCandidates []string
+	for _, branch := range candidates {
+		if !mergedBranchesMap[branch] {
+			unmergedCandidates = append(unmergedCandidates, branch)
+		}
+	}
+	squashMergedMap := detectSquashMergedBranches(mergeBase, unmergedCandidates)
 
-	for _, d := range details {
-		fmt.Printf("%-20s %-8.8s %-20s %-25s %s\n", d.Name, d.Hash, d.Author, d.Date, d.Message)
+	// Patch-id equivalence costs a git cherry per branch, so it is opt-in
+	rebaseMergedMap := make(map[string]bool)
+	if *detectRebaseMergesFlag {
+		var notSquashMerged []string
+		for _, branch := range unmergedCandidates {
+			if !squashMergedMap[branch] {
+				notSquashMerged = append(notSquashMerged, branch)
+			}
+		}
+		rebaseMergedMap = detectRebaseMergedBranches(mergeBase, notSquashMerged)
 	}
-	fmt.Println(strings.Repeat("-", 90))
 
-	// Use survey.Confirm for final confirmation
-	confirmPrompt := &survey.Confirm{
-		Message: "Proceed with deletion?",
-		Default: false,
+	// Merged branches have nothing ahead of the base by definition, so only count the others
+	aheadOfBase := make(map[string]int)
+	if *noAheadFlag {
+		aheadOfBase = getAheadCounts(mergeBase, unmergedCandidates)
+	}
+	// Reading the reflog of every candidate is only needed for --created-before
+	creationDates := make(map[string]time.Time)
+	if *createdBeforeFlag != "" {
+		creationDates = getBranchCreationDates(candidates)
 	}
-	var confirm bool
-	survey.AskOne(confirmPrompt, &confirm)
 
-	if !confirm {
-		cancelMsg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"})
-		fmt.Println(cancelMsg)
-		os.Exit(0)
+	// Unmerged branches that are an early slice of another local branch lose nothing when deleted
+	containedIn := make(map[string]string)
+	if *containedFlag {
+		var unmergedInfos []BranchInfo
+		for _, branch := range unmergedCandidates {
+			if !squashMergedMap[branch] && !rebaseMergedMap[branch] {
+				unmergedInfos = append(unmergedInfos, branchInfos[branch])
+			}
+		}
+		containedIn = findContainingBranches(unmergedInfos)
 	}
 
-	// Proceed with deletion
-	for _, branch := range branchesToDelete {
-		deleteCmd := exec.Command("git", "branch", "-d", branch)
-		deleteOutput, err := deleteCmd.CombinedOutput()
-		if err != nil {
-			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
-				MessageID: "ErrorDeletingBranch",
-				TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+	// When each branch forked off the base. Branches without a common ancestor are orphans.
+	divergenceDates := make(map[string]time.Time)
+	orphanBranches := make(map[string]bool)
+	if *divergedBeforeFlag != "" {
+		divergenceDates, orphanBranches = getDivergenceDates(mergeBase, candidates)
+	}
+	stopSpinner()
+
+	// Checking the paths runs a git log per branch, so the base is resolved only once
+	var touchingPaths map[string]bool
+	if len(pathFlag) > 0 {
+		resolvedBase := mergeBase
+		if output, err := newCommand("git", "rev-parse", mergeBase+"^{commit}").Output(); err == nil {
+			resolvedBase = strings.TrimSpace(string(output))
+		}
+		showProgress := isTerminal(os.Stderr)
+		touchingPaths = findBranchesTouchingPaths(resolvedBase, candidates, pathFlag, func(done, total int) {
+			if showProgress {
+				msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+					MessageID:    "CheckingPaths",
+					TemplateData: map[string]interface{}{"Done": done, "Total": total},
+				})
+				fmt.Fprintf(os.Stderr, "\r%s", msg)
+			}
+		})
+		if showProgress {
+			// Clear the progress line
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+	}
+
+	// Reachability from the remotes is only computed when it is asked for
+	checkPushed := *pushedFlag || *unpushedFlag
+	var pushedBranches map[string]bool
+	if checkPushed {
+		stopSpinner := startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "AnalyzingBranches",
+			TemplateData: map[string]interface{}{"Count": len(candidates)},
+		}))
+		pushedBranches = getPushedBranches(candidates)
+		stopSpinner()
+	}
+
+	// noCommitsAhead reports whether deleting the branch loses no commit that is not on the base
+	noCommitsAhead := func(branch string) bool {
+		count, ok := aheadOfBase[branch]
+		return mergedBranchesMap[branch] || (ok && count == 0)
+	}
+
+	var filtered []string
+	for _, branch := range candidates {
+		merged := mergedBranchesMap[branch] || squashMergedMap[branch] || rebaseMergedMap[branch]
+		if *mergedFlag && !merged {
+			continue
+		}
+		if *unmergedFlag && merged {
+			continue
+		}
+		if *goneFlag && !branchInfos[branch].Gone {
+			continue
+		}
+		if *cleanupFlag && !merged && !branchInfos[branch].Gone {
+			continue
+		}
+		if *noAheadFlag && !noCommitsAhead(branch) {
+			continue
+		}
+		if created, ok := creationDates[branch]; *createdBeforeFlag != "" && (ok && !created.Before(createdBefore) || !ok && *strictFlag) {
+			continue
+		}
+		if diverged, ok := divergenceDates[branch]; ok && time.Since(diverged) < divergedBefore {
+			continue
+		}
+		if len(pathFlag) > 0 && !touchingPaths[branch] {
+			continue
+		}
+		if _, ok := duplicateOf[branch]; *duplicatesFlag && !ok {
+			continue
+		}
+		if *pushedFlag && !pushedBranches[branch] {
+			continue
+		}
+		if *unpushedFlag && pushedBranches[branch] {
+			continue
+		}
+		filtered = append(filtered, branch)
+	}
+
+	// The most recently active branches always survive, whatever the other filters decided
+	if *keepRecentFlag > 0 {
+		var recent []BranchInfo
+		for _, info := range allBranches {
+			if !(!info.Remote && info.Name == currentBranch) && !isProtectedBranch(info, protectedBranches) {
+				recent = append(recent, info)
+			}
+		}
+		sort.SliceStable(recent, func(i, j int) bool {
+			return recent[i].CommitterDate.After(recent[j].CommitterDate)
+		})
+		keep := make(map[string]bool)
+		for _, info := range recent[:min(*keepRecentFlag, len(recent))] {
+			keep[info.Name] = true
+		}
+		var heldBack []string
+		filtered = slices.DeleteFunc(filtered, func(branch string) bool {
+			if keep[branch] {
+				heldBack = append(heldBack, branch)
+				return true
+			}
+			return false
+		})
+		if len(heldBack) > 0 && !*quietFlag {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "KeepingRecentBranches",
+				TemplateData: map[string]interface{}{"Branches": strings.Join(heldBack, ", ")},
 			})
+			fmt.Fprintln(diagnostics, msg)
+		}
+	}
+
+	// The cap is applied last so it always selects from the fully filtered, sorted list
+	if *maxCountFlag > 0 && len(filtered) > *maxCountFlag {
+		if !*quietFlag {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "ShowingMaxCount",
+				TemplateData: map[string]interface{}{"Shown": *maxCountFlag, "Total": len(filtered)},
+			})
+			fmt.Fprintln(diagnostics, msg)
+		}
+		filtered = filtered[:*maxCountFlag]
+	}
+
+	// The counts against the base are one rev-list per branch, so they are only computed when asked for
+	var aheadBehind map[string][2]int
+	if *showAheadBehindFlag {
+		stopSpinner := startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "AnalyzingBranches",
+			TemplateData: map[string]interface{}{"Count": len(filtered)},
+		}))
+		aheadBehind = getAheadBehindCounts(mergeBase, filtered)
+		stopSpinner()
+	}
+
+	var mergedItems, unmergedItems []string
+	now := time.Now()
+	// Counted for the fzf header
+	safeCount, unsafeCount := 0, 0
+	items := make(map[string]string)
+	// The merge status of each listed branch, shown again in the confirmation table
+	statuses := make(map[string]string)
+	safeBranches := make(map[string]bool)
+	statusKeys := make(map[string]string)
+	for _, branch := range filtered {
+		indicator := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UnmergedIndicator"})
+		// Safe branches can be deleted without losing any commit. The status key is the
+		// untranslated status --list-format=tsv prints.
+		safe := false
+		statusKey := "unmerged"
+		if mergedBranchesMap[branch] {
+			indicator = localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "MergedIndicator"})
+			safe, statusKey = true, "merged"
+		} else if squashMergedMap[branch] {
+			indicator = localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "SquashMergedIndicator"})
+			safe, statusKey = true, "squash-merged"
+		} else if rebaseMergedMap[branch] {
+			indicator = localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RebaseMergedIndicator"})
+			safe, statusKey = true, "rebase-merged"
+		} else if noCommitsAhead(branch) {
+			indicator = localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "NoAheadIndicator"})
+			safe, statusKey = true, "no-ahead"
+		} else if container, ok := containedIn[branch]; ok {
+			indicator = localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ContainedIndicator",
+				TemplateData: map[string]interface{}{"Branch": container},
+			})
+			safe, statusKey = true, "contained"
+		}
+		statusKeys[branch] = statusKey
+		statuses[branch] = strings.TrimSuffix(strings.TrimPrefix(indicator, "("), ")")
+		safeBranches[branch] = safe
+		color := ColorUnmerged
+		if safe {
+			color = ColorMerged
+			safeCount++
+		} else {
+			unsafeCount++
+		}
+		if tracking := trackingIndicator(localizer, branchInfos[branch], color); tracking != "" {
+			indicator += " " + tracking
+		}
+		if !branchInfos[branch].Remote && branch == currentBranch {
+			indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "CurrentIndicator"})
+		}
+		if !branchInfos[branch].Remote && branchInfos[branch].Upstream == "" {
+			indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "LocalOnlyIndicator"})
+		}
+		if checkPushed && !branchInfos[branch].Remote {
+			if pushedBranches[branch] {
+				indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "PushedIndicator"})
+			} else {
+				indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UnpushedIndicator"})
+			}
+		}
+		if worktreePath, ok := worktreeBranches[branch]; ok && !branchInfos[branch].Remote {
+			indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "WorktreeIndicator",
+				TemplateData: map[string]interface{}{"Path": worktreePath},
+			})
+		}
+		if orphanBranches[branch] {
+			indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "OrphanIndicator"})
+		}
+		if survivor, ok := duplicateOf[branch]; ok {
+			indicator += " " + localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "DuplicateIndicator",
+				TemplateData: map[string]interface{}{"Branch": survivor},
+			})
+		}
+		if description := descriptions[branch]; description != "" {
+			indicator += " · " + truncateWidth(firstLine(description), 50)
+		}
+		item := fmt.Sprintf("%s%s %s%s", color, branch, indicator, ColorReset)
+		if branchInfos[branch].Remote {
+			// Remote-tracking refs must not be mistaken for the local branch of the same name
+			remoteTag := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RemoteIndicator"})
+			item = fmt.Sprintf("%s%s %s%s %s%s%s", ColorRemote, branch, remoteTag, ColorReset, color, indicator, ColorReset)
+		}
+		if counts, ok := aheadBehind[branch]; ok {
+			aheadColor := ColorDim
+			if counts[0] == 0 {
+				aheadColor = ColorMerged
+			}
+			item += fmt.Sprintf(" %s↑%d%s %s↓%d%s", aheadColor, counts[0], ColorReset, ColorDim, counts[1], ColorReset)
+		}
+		if !*noAuthorsFlag && branchInfos[branch].AuthorName != "" {
+			item += " " + ColorDim + "· " + padWidth(branchInfos[branch].AuthorName, authorColumnWidth) + ColorReset
+		}
+		if !*noDatesFlag && !branchInfos[branch].CommitterDate.IsZero() {
+			item += " " + ColorDim + "· " + formatDate(localizer, branchInfos[branch].CommitterDate, *dateFlag, now) + ColorReset
+		}
+		// The branch name is the hidden key, the text is free to change
+		item = keyedLine(branch, item)
+		items[branch] = item
+		if safe || !*groupByStatusFlag {
+			mergedItems = append(mergedItems, item)
+		} else {
+			unmergedItems = append(unmergedItems, item)
+		}
+	}
+
+	// Safe-to-delete branches come first, separated from the risky ones by a divider
+	fzfItems := mergedItems
+	if len(mergedItems) > 0 && len(unmergedItems) > 0 {
+		fzfItems = append(fzfItems, keyedLine("", ColorDim+statusDivider+ColorReset))
+	}
+	fzfItems = append(fzfItems, unmergedItems...)
+	if *groupByPrefixFlag {
+		fzfItems = groupItemsByPrefix(filtered, items)
+	}
+
+	// --list prints the candidates for scripts instead of asking
+	if *listFlag {
+		terminator := "\n"
+		if *nulFlag {
+			terminator = "\x00"
+		}
+		var entries []listEntry
+		for _, branch := range filtered {
+			entries = append(entries, listEntry{
+				Name:   branch,
+				Status: statusKeys[branch],
+				Date:   branchInfos[branch].CommitterDate,
+				Safe:   safeBranches[branch],
+			})
+		}
+		printBranchList(os.Stdout, entries, *listFormatFlag, terminator)
+		return 0
+	}
+
+	if len(fzfItems) == 0 {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesToDelete"})
+		fmt.Println(msg)
+		return 0
+	}
+
+	// Declining the confirmation can go back to the selection, which starts with the query and
+	// the picks of the last one. The candidates are kept as they are.
+	pickerQuery := *queryFlag
+	var preselected map[string]bool
+	// Branches skipped by an earlier round are skipped once more by the next one
+	skippedBeforeSelection := len(summary.Skipped)
+
+	// The terminal of the prompts and the temp files of fzf are set up once for all rounds.
+	// When stdin carries the branch list, the picker and the prompts read the terminal instead.
+	useFzf := !skipFzf && !*tuiFlag && !useSurveyPicker
+	var surveyStdio []survey.AskOpt
+	if *stdinFlag || *stdin0Flag || (!skipFzf && !*tuiFlag && useSurveyPicker && !isTerminal(os.Stdin)) {
+		tty, err := os.Open("/dev/tty")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening terminal: %v\n", err)
+			return 1
+		}
+		defer tty.Close()
+		surveyStdio = append(surveyStdio, survey.WithStdio(tty, os.Stdout, os.Stderr))
+	}
+	// ctrl-f toggles the branch in the force file, rewrites the items file with the [FORCE] tag
+	// shown or not and reloads it. ctrl-o switches the preview mode in the mode file.
+	var itemsFile, forceFile, modeFile string
+	if useFzf {
+		if file, err := os.CreateTemp("", "git-delete-branch-*"); err == nil {
+			file.Close()
+			itemsFile = file.Name()
+			if file, err := os.CreateTemp("", "git-delete-branch-force-*"); err == nil {
+				file.Close()
+				forceFile = file.Name()
+			} else {
+				os.Remove(itemsFile)
+				itemsFile = ""
+				fmt.Fprintf(os.Stderr, "Warning: ctrl-f is disabled: %v\n", err)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: ctrl-f is disabled: %v\n", err)
+		}
+		if !*noPreviewFlag {
+			if file, err := os.CreateTemp("", "git-delete-branch-preview-*"); err == nil {
+				file.Close()
+				modeFile = file.Name()
+			}
+		}
+	}
+	removeTempFiles := func() {
+		for _, path := range []string{itemsFile, forceFile, modeFile} {
+			if path != "" {
+				os.Remove(path)
+			}
+		}
+		itemsFile, forceFile, modeFile = "", "", ""
+	}
+	defer removeTempFiles()
+
+	// Branches toggled with ctrl-f in the last fzf round
+	var toggledForce map[string]bool
+
+selection:
+	var selectedItems []string
+	if skipFzf {
+		// Branches given on the command line or found by --cleanup are taken as the selection
+		selectedItems = filtered
+	} else if *tuiFlag {
+		prompt, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "FzfPrompt",
+			TemplateData: map[string]interface{}{"Count": len(filtered)},
+			PluralCount:  len(filtered),
+		})
+		help, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "TUIHelp"})
+		var preview func(string) string
+		if !*noPreviewFlag {
+			previewBase := base
+			if previewBase == "" {
+				previewBase = getSwitchTarget("")
+			}
+			preview = func(branch string) string {
+				output, err := newCommand("git", previewLogArgs(branch, previewBase, *previewFormatFlag, *previewLimitFlag)...).Output()
+				if err != nil {
+					return err.Error()
+				}
+				return string(output)
+			}
+		}
+		pickerQuery, selectedItems, err = runTUI(fzfItems, pickerQuery, preselected, prompt, help, preview)
+		if err == errFzfCancelled {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error selecting branches: %v\n", err)
+			return 1
+		}
+	} else if useSurveyPicker {
+		message := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "SelectBranchesPrompt"})
+		selectedItems, err = runSurveyPicker(message, fzfItems, preselected, surveyStdio)
+		if err == errFzfCancelled {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error selecting branches: %v\n", err)
+			return 1
+		}
+	} else {
+		// Prepare fzf command
+		// Use os.Args[0] to get the path to the current executable
+		executablePath, err := os.Executable()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting executable path: %v\n", err)
+			return 1
+		}
+
+		// The preview runs in a child process, so forward the options it depends on
+		previewCmd := shellQuote(executablePath)
+		if *langFlag != "" {
+			previewCmd += " -lang " + shellQuote(*langFlag)
+		}
+		if base != "" {
+			previewCmd += " -base " + shellQuote(base)
+		}
+		if *verboseFlag {
+			// The commands of the preview are logged above its output
+			previewCmd += " -verbose"
+		}
+		if !colorsEnabled {
+			previewCmd += " -no-color"
+		} else if *themeFlag != "default" {
+			previewCmd += " -theme " + shellQuote(*themeFlag)
+		}
+		previewCmd += " -preview-format " + shellQuote(*previewFormatFlag) + " -preview-limit " + fmt.Sprint(*previewLimitFlag)
+		// ctrl-o switches the mode in the mode file and redraws the preview. fzf binds ctrl-p to
+		// moving up, so the mode has a key of its own.
+		if modeFile != "" {
+			previewCmd += " -preview-mode-file " + shellQuote(modeFile)
+		}
+		previewCmd += " -get-log {1}"
+
+		fzfArgs := append([]string{"--multi"}, fzfColorArgs()...)
+		fzfArgs = append(fzfArgs, fzfKeyArgs...)
+		// Keys bound below, for the key help of the header. fzf binds Tab and Enter itself.
+		boundKeys := map[string]bool{"tab": true, "enter": true}
+		if !*noPreviewFlag {
+			fzfArgs = append(fzfArgs, "--preview", previewCmd)
+			// The spec only changes the pane, the preview command stays the same
+			if *previewWindowFlag != "" {
+				fzfArgs = append(fzfArgs, "--preview-window", *previewWindowFlag)
+			}
+			if modeFile != "" {
+				cycleCmd := shellQuote(executablePath) + " -cycle-preview-mode " + shellQuote(modeFile)
+				fzfArgs = append(fzfArgs, "--bind", "ctrl-o:execute-silent("+cycleCmd+")+refresh-preview")
+				boundKeys["ctrl-o"] = true
+			}
+		}
+
+		// The items file starts each round with the lines of this one, and nothing toggled
+		if itemsFile != "" {
+			err := os.WriteFile(itemsFile, []byte(strings.Join(fzfItems, "\n")), 0o600)
+			if err == nil {
+				err = os.WriteFile(forceFile, nil, 0o600)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: ctrl-f is disabled: %v\n", err)
+			} else {
+				toggleCmd := shellQuote(executablePath) + " -toggle-force " + shellQuote(itemsFile) + " -force-file " + shellQuote(forceFile) + " {1}"
+				fzfArgs = append(fzfArgs, "--bind", "ctrl-f:execute-silent("+toggleCmd+")+reload(cat "+shellQuote(itemsFile)+")")
+				boundKeys["ctrl-f"] = true
+			}
+		}
+		// select-all only selects what matches the query, so typing a prefix first narrows it down.
+		// Dividers and group headers it selects are ignored.
+		fzfArgs = append(fzfArgs, "--bind", "ctrl-a:select-all,ctrl-d:deselect-all")
+		boundKeys["ctrl-a"], boundKeys["ctrl-d"] = true, true
+		if len(preselected) > 0 && fzfVersionAtLeast(0, 40) {
+			fzfArgs = append(fzfArgs, "--bind", fzfPreselectBind(fzfItems, preselected, pickerQuery))
+		} else if pickerQuery != "" {
+			fzfArgs = append(fzfArgs, "--query", pickerQuery)
+		}
+		// The fzf texts fall back to English when a locale lacks them, so they are not MustLocalize
+		// The candidates are counted once, after every filter
+		prompt, _ := localizer.Localize(&i18n.LocalizeConfig{
+			MessageID:    "FzfPrompt",
+			TemplateData: map[string]interface{}{"Count": len(filtered)},
+			PluralCount:  len(filtered),
+		})
+		fzfArgs = append(fzfArgs, "--prompt", prompt)
+		if !*noPreviewFlag && fzfVersionAtLeast(0, 35) {
+			previewLabel, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "FzfPreviewLabel"})
+			fzfArgs = append(fzfArgs, "--preview-label", previewLabel)
+		}
+		// User options come last, so fzf lets them override the ones above
+		var userFzfArgs []string
+		if opts := os.Getenv("GIT_DELETE_BRANCH_FZF_OPTS"); opts != "" {
+			words, err := splitShellWords(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring GIT_DELETE_BRANCH_FZF_OPTS: %v\n", err)
+			}
+			userFzfArgs = append(userFzfArgs, words...)
+		}
+		userFzfArgs = append(userFzfArgs, fzfArgFlag...)
+		// Keys the user binds to something else are not named in the key help
+		for key := range fzfReboundKeys(userFzfArgs) {
+			delete(boundKeys, key)
+		}
+		// The header names the keys bound above, the counts and, with colors, what they mean.
+		// The key help and the legend are dropped first when the terminal is narrow, then the
+		// whole header. Japanese text takes two columns per character.
+		counts := map[string]interface{}{"Merged": safeCount, "Unmerged": unsafeCount}
+		countsHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "FzfHeaderShort", TemplateData: counts})
+		headerParts := []string{fzfHeaderKeys(localizer, boundKeys), countsHeader}
+		if colorsEnabled {
+			headerParts = append(headerParts, colorLegend(localizer))
+		}
+		header := strings.Join(slices.DeleteFunc(headerParts, func(part string) bool { return part == "" }), " · ")
+		width := terminalWidth()
+		if width > 0 && displayWidth(header) > width {
+			header = countsHeader
+		}
+		if width == 0 || displayWidth(header) <= width {
+			fzfArgs = append(fzfArgs, "--header", header)
+		}
+		fzfArgs = append(fzfArgs, userFzfArgs...)
+
+		pickerQuery, selectedItems, err = runFzfWithQuery(fzfArgs, fzfItems)
+		if err == errFzfCancelled {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error running fzf: %v\n", err)
+			return 1
+		}
+		// The force file is the only record of ctrl-f, the [FORCE] tag may be part of any text
+		toggledForce = nil
+		if forceFile != "" {
+			if toggledForce, err = readForceFile(forceFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading the branches toggled for force deletion: %v\n", err)
+				return 1
+			}
+		}
+	}
+
+	// Taking branches off the list after declining the confirmation comes back here
+confirmation:
+	summary.Skipped = summary.Skipped[:skippedBeforeSelection]
+	if len(selectedItems) == 0 {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesSelected"})
+		fmt.Println(msg)
+		return 0
+	}
+
+	// Selections are mapped back to the branches by the key of their line
+	var branchesToDelete []string
+	forceBranches := make(map[string]bool)
+	for _, selectedItem := range selectedItems {
+		branchName := lineKey(selectedItem)
+		// Selecting a divider or group header is a no-op
+		if _, ok := items[branchName]; !ok {
+			continue
+		}
+		if isProtectedBranch(branchInfos[branchName], protectedBranches) {
+			summary.Skipped = append(summary.Skipped, branchName)
+			continue
+		}
+		branchesToDelete = append(branchesToDelete, branchName)
+		if *forceFlag || toggledForce[branchName] {
+			forceBranches[branchName] = true
+		}
+	}
+
+	// Force deleting a branch that exists nowhere else loses its commits, unless they are kept
+	// under refs/trash/ or as an archive tag anyway
+	unpushedBranches := make(map[string]bool)
+	if !*allowUnpushedFlag && !*trashFlag && !*archiveFlag {
+		var localInfos []BranchInfo
+		for _, branch := range branchesToDelete {
+			if !branchInfos[branch].Remote {
+				localInfos = append(localInfos, branchInfos[branch])
+			}
+		}
+		unpushedBranches = getUnpushedBranches(localInfos)
+	}
+
+	// Get details for selected branches. The errors are printed once the spinner is gone.
+	stopSpinner = startSpinner(localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "CollectingDetails",
+		TemplateData: map[string]interface{}{"Count": len(branchesToDelete)},
+	}))
+	var details []BranchDetail
+	var detailErrors []string
+	for _, branchName := range branchesToDelete {
+		detail, err := getBranchDetail(branchName)
+		if err != nil {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorGettingBranchDetails",
+				TemplateData: map[string]interface{}{"Branch": branchName, "Error": err},
+			})
+			detailErrors = append(detailErrors, msg)
+			continue
+		}
+		details = append(details, detail)
+	}
+
+	// Creation dates of the selected branches that were not already read for --created-before
+	var missingCreationDates []string
+	for _, d := range details {
+		if _, ok := creationDates[d.Name]; !ok {
+			missingCreationDates = append(missingCreationDates, d.Name)
+		}
+	}
+	for branch, created := range getBranchCreationDates(missingCreationDates) {
+		creationDates[branch] = created
+	}
+	stopSpinner()
+	for _, msg := range detailErrors {
+		fmt.Println(msg)
+	}
+
+	if len(details) == 0 {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "NoBranchesSelected"})
+		fmt.Println(msg)
+		return 0
+	}
+
+	// Display confirmation
+	confirmMessageID := "ConfirmDeletion"
+	if *archiveFlag {
+		confirmMessageID = "ConfirmArchiveDeletion"
+	}
+	if *trashFlag {
+		confirmMessageID = "ConfirmTrashDeletion"
+	}
+	confirmMsg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: confirmMessageID})
+	fmt.Printf("\n%s\n", confirmMsg)
+
+	branchHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Branch"})
+	hashHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Hash"})
+	authorHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Author"})
+	dateHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Date"})
+	messageHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Message"})
+	descriptionHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Description"})
+	createdHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Created"})
+	duplicateOfHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "DuplicateOf"})
+	unpushedTag := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UnpushedWarningTag"})
+	divergedHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Diverged"})
+	orphanLabel, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Orphan"})
+	unknownCreated, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "UnknownCreated"})
+	deleteFlagHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "DeleteFlag"})
+	remoteHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "RemoteBranch"})
+	archiveTagHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "ArchiveTag"})
+	worktreeHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Worktree"})
+	trackingHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Tracking"})
+	statusHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "Status"})
+	uniqueHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "UniqueCommits"})
+	kindHeader, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "BranchKind"})
+	localKind, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "LocalBranchKind"})
+	remoteKind, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "RemoteBranchKind"})
+
+	// The description column is only shown when one of the branches has a description
+	showDescriptions := slices.ContainsFunc(details, func(d BranchDetail) bool { return descriptions[d.Name] != "" })
+	// Likewise the surviving copy is only shown when a duplicate was selected
+	showDuplicates := slices.ContainsFunc(details, func(d BranchDetail) bool { return duplicateOf[d.Name] != "" })
+
+	// The tracking status is only shown when a branch is ahead of, behind or gone from its upstream
+	showTracking := slices.ContainsFunc(details, func(d BranchDetail) bool {
+		info := branchInfos[d.Name]
+		return info.Gone || info.Ahead > 0 || info.Behind > 0
+	})
+
+	// Local branches and remote-tracking refs are told apart when any of the latter was selected
+	showKind := slices.ContainsFunc(details, func(d BranchDetail) bool { return branchInfos[d.Name].Remote })
+
+	// Whether -d, -D or -rd is used is only worth a column when some branches are force deleted
+	// or remote-tracking refs are mixed in
+	showDeleteFlag := len(forceBranches) > 0 || showKind
+	// Worktrees have to be removed before their branch can be deleted
+	showWorktrees := slices.ContainsFunc(details, func(d BranchDetail) bool {
+		_, ok := worktreeBranches[d.Name]
+		return ok && !branchInfos[d.Name].Remote
+	})
+
+	// The divergence date is only known when --diverged-before was given
+	showDiverged := *divergedBeforeFlag != ""
+
+	// Commits that are on the selected branches only, counted in parallel
+	var selectedNames []string
+	for _, d := range details {
+		selectedNames = append(selectedNames, d.Name)
+	}
+	uniqueCommits := getAheadCounts(mergeBase, selectedNames)
+
+	var confirmTable table
+	if !*noTruncateFlag {
+		confirmTable.width = tableWidthLimit()
+	}
+	confirmTable.addColumn(branchHeader, 40)
+	if showKind {
+		confirmTable.addColumn(kindHeader, 0)
+	}
+	confirmTable.addColumn(statusHeader, 24)
+	confirmTable.addColumn(uniqueHeader, 0)
+	confirmTable.addColumn(hashHeader, 0)
+	confirmTable.addColumn(authorHeader, 20)
+	confirmTable.addColumn(dateHeader, 0)
+	confirmTable.addColumn(createdHeader, 0)
+	if showDeleteFlag {
+		confirmTable.addColumn(deleteFlagHeader, 0)
+	}
+	if showWorktrees {
+		confirmTable.addColumn(worktreeHeader, 40)
+	}
+	if *archiveFlag {
+		confirmTable.addColumn(archiveTagHeader, 0)
+	}
+	if *remoteFlag {
+		confirmTable.addColumn(remoteHeader, 40)
+	}
+	if showTracking {
+		confirmTable.addColumn(trackingHeader, 0)
+	}
+	if showDiverged {
+		confirmTable.addColumn(divergedHeader, 0)
+	}
+	if showDuplicates {
+		confirmTable.addColumn(duplicateOfHeader, 40)
+	}
+	if showDescriptions {
+		confirmTable.addColumn(descriptionHeader, 30)
+	}
+	confirmTable.addColumn(messageHeader, 0)
+
+	for _, d := range details {
+		created := unknownCreated
+		if date, ok := creationDates[d.Name]; ok {
+			created = date.Format("2006-01-02 15:04")
+		}
+		date := d.Date
+		if *dateFlag != "default" {
+			date = formatDate(localizer, d.AuthorDate, *dateFlag, now)
+		}
+		// The name and status are colored by risk: branches whose upstream is gone in the gone
+		// color, whether merged or not, then safe ones in the merged color and branches that
+		// lose commits in the unmerged color
+		name, status := d.Name, statuses[d.Name]
+		if status != "" {
+			riskColor := ColorUnmerged
+			if branchInfos[d.Name].Gone {
+				riskColor = ColorGone
+			} else if safeBranches[d.Name] {
+				riskColor = ColorMerged
+			}
+			name = riskColor + name + ColorReset
+			status = riskColor + status + ColorReset
+		}
+		unique := "?"
+		if count, ok := uniqueCommits[d.Name]; ok {
+			unique = uniqueCommitsColor(count, safeBranches[d.Name]) + strconv.Itoa(count) + ColorReset
+		}
+		row := []string{name}
+		if showKind {
+			kind := localKind
+			if branchInfos[d.Name].Remote {
+				kind = ColorRemote + remoteKind + ColorReset
+			}
+			row = append(row, kind)
+		}
+		row = append(row, status, unique, d.Hash[:min(len(d.Hash), 8)], d.Author, date, created)
+		if showDeleteFlag {
+			deleteFlag := "-d"
+			if branchInfos[d.Name].Remote {
+				deleteFlag = "-rd"
+			} else if forceBranches[d.Name] {
+				deleteFlag = "-D"
+			}
+			row = append(row, deleteFlag)
+		}
+		if showWorktrees {
+			worktreePath := ""
+			if !branchInfos[d.Name].Remote {
+				worktreePath = worktreeBranches[d.Name]
+			}
+			row = append(row, worktreePath)
+		}
+		if *archiveFlag {
+			row = append(row, archiveTagName(d.Name))
+		}
+		if *remoteFlag {
+			remoteBranch := ""
+			if info := branchInfos[d.Name]; info.HasRemoteUpstream() {
+				remoteBranch = info.UpstreamRemote + "/" + info.UpstreamBranch
+			}
+			row = append(row, remoteBranch)
+		}
+		if showTracking {
+			row = append(row, trackingIndicator(localizer, branchInfos[d.Name], ColorReset))
+		}
+		if showDiverged {
+			diverged := orphanLabel
+			if date, ok := divergenceDates[d.Name]; ok {
+				diverged = date.Format("2006-01-02 15:04")
+			}
+			row = append(row, diverged)
+		}
+		if showDuplicates {
+			row = append(row, duplicateOf[d.Name])
+		}
+		if showDescriptions {
+			row = append(row, firstLine(descriptions[d.Name]))
+		}
+		message := d.Message
+		if forceBranches[d.Name] && unpushedBranches[d.Name] {
+			message = ColorWarning + unpushedTag + ColorReset + " " + message
+		}
+		confirmTable.addRow(append(row, message)...)
+	}
+	// A table taller than the terminal is paged, so its header does not scroll away before the prompt
+	var tableText bytes.Buffer
+	confirmTable.render(&tableText)
+	// The columns are aligned without the color codes, so the stripped table lines up as well
+	if !isTerminal(os.Stdout) {
+		stripped := ansiStripper.ReplaceAll(tableText.Bytes(), nil)
+		tableText.Reset()
+		tableText.Write(stripped)
+	}
+	if *yesFlag || *dryRunFlag {
+		os.Stdout.Write(tableText.Bytes())
+	} else if printPaged(tableText.Bytes()) {
+		mergedCount := 0
+		for _, d := range details {
+			if safeBranches[d.Name] {
+				mergedCount++
+			}
+		}
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "TableSummary",
+			TemplateData: map[string]interface{}{"Count": len(details), "Merged": mergedCount, "Unmerged": len(details) - mergedCount},
+		}))
+	}
+	if *forceFlag {
+		warning := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "ForceDeletionWarning"})
+		fmt.Println(ColorWarning + warning + ColorReset)
+	}
+
+	// Refused force deletions are skipped before anything else happens
+	var refusedBranches []string
+	for _, branch := range branchesToDelete {
+		if forceBranches[branch] && unpushedBranches[branch] {
+			refusedBranches = append(refusedBranches, branch)
+		}
+	}
+	if len(refusedBranches) > 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "UnpushedForceRefused"}))
+		for _, branch := range refusedBranches {
+			fmt.Println("  " + branch)
+		}
+		summary.Skipped = append(summary.Skipped, refusedBranches...)
+		branchesToDelete = slices.DeleteFunc(branchesToDelete, func(b string) bool { return slices.Contains(refusedBranches, b) })
+		if len(branchesToDelete) == 0 {
+			summary.Print(localizer, *remoteFlag)
+			return 0
+		}
+	}
+
+	// Stashes survive the branch, but without it they are easily forgotten
+	var localBranches []string
+	for _, branch := range branchesToDelete {
+		if !branchInfos[branch].Remote {
+			localBranches = append(localBranches, branch)
+		}
+	}
+	stashes := getStashes(localBranches)
+	if len(stashes) > 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "StashesOnBranchesWarning"}))
+		for _, stash := range stashes {
+			fmt.Printf("  %s  %s\n", stash.Ref(), stash.Message)
+		}
+	}
+
+	// deleteArgs returns the git arguments that delete the branch
+	deleteArgs := func(branch string) []string {
+		if branchInfos[branch].Remote {
+			// Only the local remote-tracking ref is removed, the remote itself is left untouched
+			return []string{"branch", "-rd", branch}
+		}
+		// A trashed branch keeps its commits in refs/trash/, so it may be unmerged
+		if forceBranches[branch] || *trashFlag {
+			return []string{"branch", "-D", branch}
+		}
+		return []string{"branch", "-d", branch}
+	}
+
+	if *dryRunFlag {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DryRunHeader"}))
+		for _, branch := range branchesToDelete {
+			if branch == currentBranch && !branchInfos[branch].Remote {
+				fmt.Println("  git switch " + shellQuote(getSwitchTarget(base)))
+			}
+			if worktreePath, ok := worktreeBranches[branch]; ok && !branchInfos[branch].Remote {
+				fmt.Println("  git worktree remove " + shellQuote(worktreePath))
+			}
+			if *archiveFlag {
+				fmt.Println("  git tag " + shellQuote(archiveTagName(branch)) + " " + branchInfos[branch].Hash)
+			}
+			if *trashFlag && !branchInfos[branch].Remote {
+				fmt.Println("  git update-ref " + shellQuote(trashRefPrefix+branch) + " " + branchInfos[branch].Hash)
+			}
+			args := deleteArgs(branch)
+			// Only the branch name may need quoting
+			fmt.Println("  git " + strings.Join(args[:len(args)-1], " ") + " " + shellQuote(branch))
+			if info := branchInfos[branch]; *remoteFlag && info.HasRemoteUpstream() {
+				if *archiveFlag {
+					fmt.Println("  git push " + shellQuote(info.UpstreamRemote) + " " + shellQuote("refs/tags/"+archiveTagName(branch)))
+				}
+				fmt.Println("  git push " + shellQuote(info.UpstreamRemote) + " --delete " + shellQuote(info.UpstreamBranch))
+			}
+		}
+		if *dropStashesFlag {
+			// Dropped from the oldest, so the newer indexes stay valid
+			for i := len(stashes) - 1; i >= 0; i-- {
+				fmt.Println("  git stash drop " + shellQuote(stashes[i].Ref()))
+			}
+		}
+		return 0
+	}
+
+	// Branches turned down with --confirm-each
+	var declinedBranches []string
+	// Whether the single confirmation for all branches was answered with no
+	declined := false
+
+	// Nobody can answer the prompts without a terminal, so only --yes may proceed
+	interactive := len(surveyStdio) > 0 || isTerminal(os.Stdin)
+	if !*yesFlag {
+		if !interactive {
+			msg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "ConfirmationRequiresTerminal"})
 			fmt.Println(msg)
-			fmt.Println(string(deleteOutput))
+			return 1
+		}
+
+		if *confirmEachFlag {
+			// Walk through the branches one at a time until "all" or "quit"
+			var accepted []string
+			for i, d := range details {
+				fmt.Println()
+				fmt.Printf("%s: %s\n%s: %s\n%s: %s\n%s: %s\n%s: %s\n", branchHeader, d.Name, hashHeader, d.Hash, authorHeader, d.Author, dateHeader, d.Date, messageHeader, d.Message)
+				answer := askChoice(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "ConfirmEachPrompt",
+					TemplateData: map[string]interface{}{"Branch": d.Name},
+				}), surveyStdio)
+				if answer == "all" {
+					for _, rest := range details[i:] {
+						accepted = append(accepted, rest.Name)
+					}
+					break
+				}
+				if answer == "quit" {
+					for _, rest := range details[i:] {
+						declinedBranches = append(declinedBranches, rest.Name)
+					}
+					break
+				}
+				if answer == "y" {
+					accepted = append(accepted, d.Name)
+				} else {
+					declinedBranches = append(declinedBranches, d.Name)
+				}
+			}
+			branchesToDelete = accepted
+		} else if *confirmThresholdFlag > 0 && len(branchesToDelete) > *confirmThresholdFlag {
+			// Many branches are not deleted with a single keystroke
+			message := localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "TypedConfirmationPrompt",
+				TemplateData: map[string]interface{}{"Count": len(branchesToDelete)},
+			})
+			declined = !askTypedConfirmation(message, len(branchesToDelete), surveyStdio)
 		} else {
+			// Use survey.Confirm for final confirmation
+			confirmPrompt := &survey.Confirm{
+				Message: localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "ProceedWithDeletion"}),
+				Default: false,
+			}
+			var confirm bool
+			survey.AskOne(confirmPrompt, &confirm, surveyStdio...)
+			declined = !confirm
+		}
+
+		if declined {
+			// Branches given on the command line or found by --cleanup have no selection to go back to
+			switch askDeclined(localizer, !skipFzf, surveyStdio) {
+			case "back":
+				// Lines whose [FORCE] tag was toggled keep it
+				preselected = make(map[string]bool)
+				for _, item := range selectedItems {
+					branch := lineKey(item)
+					preselected[branch] = true
+					if i := slices.IndexFunc(fzfItems, func(line string) bool { return lineKey(line) == branch }); i >= 0 {
+						fzfItems[i] = item
+					}
+				}
+				goto selection
+			case "edit":
+				for i, branch := range branchesToDelete {
+					fmt.Printf("  %3d  %s\n", i+1, branch)
+				}
+				message := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RemoveRowsPrompt"})
+				removed := make(map[string]bool)
+				for _, i := range askRowNumbers(message, len(branchesToDelete), surveyStdio) {
+					removed[branchesToDelete[i]] = true
+				}
+				// With skipFzf the selection shares its array with filtered
+				selectedItems = slices.DeleteFunc(slices.Clone(selectedItems), func(item string) bool { return removed[lineKey(item)] })
+				if len(selectedItems) == 0 && !skipFzf {
+					preselected = nil
+					goto selection
+				}
+				goto confirmation
+			}
+			cancelMsg, _ := localizer.Localize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"})
+			fmt.Println(cancelMsg)
+			return 0
+		}
+	}
+	// There is no going back to the selection from here
+	removeTempFiles()
+
+	// Base named in the force delete prompt
+	baseName := mergeBase
+	if baseName == "HEAD" && currentBranch != "" {
+		baseName = currentBranch
+	}
+	// With --yes failures are only reported
+	askForce := interactive && !*yesFlag
+	forceAll := false
+
+	// The current branch can only be deleted after switching away from it
+	if slices.Contains(branchesToDelete, currentBranch) && !branchInfos[currentBranch].Remote {
+		target := getSwitchTarget(base)
+		var switchErr error
+		var switchOutput []byte
+		switch {
+		case isWorkingTreeDirty():
+			switchErr = errors.New(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "WorkingTreeDirty"}))
+		case target == "" || target == currentBranch:
+			switchErr = errors.New(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "NoBranchToSwitchTo"}))
+		default:
+			switchOutput, switchErr = newCommand("git", "switch", target).CombinedOutput()
+		}
+		if switchErr != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorSwitchingFromCurrent",
+				TemplateData: map[string]interface{}{"Branch": currentBranch, "Error": switchErr},
+			}))
+			fmt.Println(string(switchOutput))
+			summary.Failed = append(summary.Failed, currentBranch)
+			branchesToDelete = slices.DeleteFunc(branchesToDelete, func(b string) bool { return b == currentBranch })
+		} else if !*quietFlag {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "SwitchedBranch",
+				TemplateData: map[string]interface{}{"Branch": target},
+			}))
+		}
+	}
+
+	// Branches checked out in another worktree can only be deleted once the worktree is removed
+	var removableBranches []string
+	for _, branch := range branchesToDelete {
+		worktreePath, ok := worktreeBranches[branch]
+		if !ok || branchInfos[branch].Remote {
+			removableBranches = append(removableBranches, branch)
+			continue
+		}
+		remove := false
+		if askForce {
+			prompt := &survey.Confirm{
+				Message: localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "RemoveWorktreePrompt",
+					TemplateData: map[string]interface{}{"Branch": branch, "Path": worktreePath},
+				}),
+			}
+			survey.AskOne(prompt, &remove, surveyStdio...)
+		}
+		if !remove {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "SkippingWorktreeBranch",
+				TemplateData: map[string]interface{}{"Branch": branch, "Path": worktreePath},
+			}))
+			summary.Skipped = append(summary.Skipped, branch)
+			continue
+		}
+		removeArgs := []string{"worktree", "remove", worktreePath}
+		if *forceFlag {
+			// Also removes worktrees with local changes
+			removeArgs = []string{"worktree", "remove", "--force", worktreePath}
+		}
+		if output, err := newCommand("git", removeArgs...).CombinedOutput(); err != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorRemovingWorktree",
+				TemplateData: map[string]interface{}{"Branch": branch, "Path": worktreePath, "Error": err},
+			}))
+			fmt.Println(string(output))
+			summary.Failed = append(summary.Failed, branch)
+			continue
+		}
+		removableBranches = append(removableBranches, branch)
+	}
+	branchesToDelete = removableBranches
+
+	// Each run backs up the deleted branches under its own timestamp
+	backupSession := newBackupSessionID(time.Now())
+
+	isMerged := func(branch string) bool {
+		return mergedBranchesMap[branch] || squashMergedMap[branch] || rebaseMergedMap[branch]
+	}
+
+	var deletionJournal *journal
+	if !*noJournalFlag {
+		var err error
+		if deletionJournal, err = openJournal(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not open the deletion journal: %v\n", err)
+		}
+		defer deletionJournal.Close()
+	}
+	// writeJournal records the outcome of deleting the branch, locally or (with remote set) on that remote
+	writeJournal := func(branch, remote string, forced bool, err error) {
+		entry := journalEntry{
+			Time:    time.Now(),
+			Session: backupSession,
+			Branch:  branch,
+			Hash:    branchInfos[branch].Hash,
+			Merged:  isMerged(branch),
+			Forced:  forced,
+			Remote:  remote,
+			Deleted: err == nil,
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if err := deletionJournal.Write(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not write to the deletion journal: %v\n", err)
+		}
+	}
+
+	// Proceed with deletion
+	var deletedBranches []BranchInfo
+	var archiveTags []string
+	// Trash ref names of the trashed branches, which differ from the branch names on collisions
+	trashed := make(map[string]string)
+	var remoteDeletions []remoteDeletion
+
+	// From here on Ctrl+C lets the current deletion finish and skips the rest instead of killing the run
+	interrupted := watchInterrupts()
+
+	// Backups and archive tags have to exist before anything is deleted
+	var prepared []string
+	for _, branch := range branchesToDelete {
+		if interrupted.Load() {
+			summary.Skipped = append(summary.Skipped, branch)
+			continue
+		}
+		// A failing pre-delete hook vetoes the deletion
+		if *preDeleteCmdFlag != "" {
+			if err := runHook(*preDeleteCmdFlag, branchInfos[branch], isMerged(branch), *verboseFlag); err != nil {
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "PreDeleteHookFailed",
+					TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+				}))
+				summary.Skipped = append(summary.Skipped, branch)
+				continue
+			}
+		}
+		if !*noBackupFlag {
+			if err := createBackup(backupSession, branchInfos[branch]); err != nil {
+				// Deleting without a backup is not what was asked for
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "ErrorCreatingBackup",
+					TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+				}))
+				writeJournal(branch, "", false, err)
+				summary.Failed = append(summary.Failed, branch)
+				continue
+			}
+		}
+		if *archiveFlag {
+			if err := createArchiveTag(branch, branchInfos[branch].Hash); err != nil {
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "ErrorCreatingArchiveTag",
+					TemplateData: map[string]interface{}{"Branch": branch, "Tag": archiveTagName(branch), "Error": err},
+				}))
+				if !*noBackupFlag {
+					removeBackup(backupSession, branch)
+				}
+				writeJournal(branch, "", false, err)
+				summary.Failed = append(summary.Failed, branch)
+				continue
+			}
+		}
+		if *trashFlag && !branchInfos[branch].Remote {
+			name, err := trashBranch(branch, branchInfos[branch].Hash)
+			if err != nil {
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "ErrorTrashingBranch",
+					TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+				}))
+				if !*noBackupFlag {
+					removeBackup(backupSession, branch)
+				}
+				if *archiveFlag {
+					deleteArchiveTag(branch)
+				}
+				writeJournal(branch, "", false, err)
+				summary.Failed = append(summary.Failed, branch)
+				continue
+			}
+			trashed[branch] = name
+		}
+		prepared = append(prepared, branch)
+	}
+
+	// Batching is only possible when nothing has to happen between two deletions
+	sequential := askForce || *failFastFlag
+	deleteResults := make(map[string]deleteResult)
+	if !sequential {
+		// Branches deleted the same way are passed to a single git branch invocation
+		var deleteFlags []string
+		branchesByFlag := make(map[string][]string)
+		for _, branch := range prepared {
+			deleteFlag := deleteArgs(branch)[1]
+			if branchesByFlag[deleteFlag] == nil {
+				deleteFlags = append(deleteFlags, deleteFlag)
+			}
+			branchesByFlag[deleteFlag] = append(branchesByFlag[deleteFlag], branch)
+		}
+		for _, deleteFlag := range deleteFlags {
+			maps.Copy(deleteResults, deleteBranches(deleteFlag, branchesByFlag[deleteFlag]))
+		}
+	}
+
+	aborted := false
+	for _, branch := range prepared {
+		// Branches of a batch were already deleted together
+		result, batched := deleteResults[branch]
+		if !batched && (aborted || interrupted.Load()) {
+			// The branch stays, so nothing prepared for its deletion is needed
+			if !*noBackupFlag {
+				removeBackup(backupSession, branch)
+			}
+			if *archiveFlag {
+				deleteArchiveTag(branch)
+			}
+			if name, ok := trashed[branch]; ok {
+				dropTrash(name)
+				delete(trashed, branch)
+			}
+			summary.Skipped = append(summary.Skipped, branch)
+			continue
+		}
+		if !batched {
+			result = deleteBranches(deleteArgs(branch)[1], []string{branch})[branch]
+		}
+		deleteOutput, err := []byte(result.Output), result.Err
+		forced := forceBranches[branch]
+		reported := false
+		refused := false
+	retry:
+		for err != nil && askForce {
 			msg, _ := localizer.Localize(&i18n.LocalizeConfig{
-				MessageID: "BranchDeletedSuccessfully",
-				TemplateData: map[string]interface{}{"Branch": branch},
+				MessageID:    "ErrorDeletingBranch",
+				TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
 			})
 			fmt.Println(msg)
 			fmt.Println(string(deleteOutput))
+			reported = true
+
+			canForce := !forced && !branchInfos[branch].Remote
+			answer := "force"
+			if !forceAll || !canForce {
+				ahead := -1
+				if canForce && strings.Contains(string(deleteOutput), "not fully merged") {
+					ahead = getAheadCounts(mergeBase, []string{branch})[branch]
+				}
+				answer = askDeleteFailure(localizer, branch, baseName, ahead, canForce, surveyStdio)
+			}
+			switch answer {
+			case "retry":
+				deleteOutput, err = uninterruptibleCommand(deleteArgs(branch)...).CombinedOutput()
+			case "force-all":
+				forceAll = true
+				fallthrough
+			case "force":
+				if unpushedBranches[branch] {
+					fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+						MessageID:    "UnpushedBranchNotForced",
+						TemplateData: map[string]interface{}{"Branch": branch},
+					}))
+					refused = true
+					break retry
+				}
+				deleteOutput, err = uninterruptibleCommand("branch", "-D", branch).CombinedOutput()
+				forced = true
+			case "abort":
+				aborted = true
+				break retry
+			default:
+				break retry
+			}
+		}
+		if err != nil && *failFastFlag {
+			aborted = true
+		}
+		writeJournal(branch, "", forced, err)
+		if err != nil {
+			if !reported {
+				msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+					MessageID:    "ErrorDeletingBranch",
+					TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+				})
+				fmt.Println(msg)
+				fmt.Println(string(deleteOutput))
+			}
+			if refused {
+				summary.Skipped = append(summary.Skipped, branch)
+			} else {
+				summary.Failed = append(summary.Failed, branch)
+			}
+			if !*noBackupFlag {
+				removeBackup(backupSession, branch)
+			}
+			if *archiveFlag {
+				// The branch itself still holds the commits
+				deleteArchiveTag(branch)
+			}
+			if name, ok := trashed[branch]; ok {
+				dropTrash(name)
+				delete(trashed, branch)
+			}
+		} else {
+			if !*quietFlag {
+				msg, _ := localizer.Localize(&i18n.LocalizeConfig{
+					MessageID:    "BranchDeletedSuccessfully",
+					TemplateData: map[string]interface{}{"Branch": branch},
+				})
+				fmt.Println(msg)
+				fmt.Println(string(deleteOutput))
+			}
+			deletedBranches = append(deletedBranches, branchInfos[branch])
+			summary.Deleted = append(summary.Deleted, branch)
+			if *postDeleteCmdFlag != "" {
+				if err := runHook(*postDeleteCmdFlag, branchInfos[branch], isMerged(branch), *verboseFlag); err != nil {
+					fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+						MessageID:    "PostDeleteHookFailed",
+						TemplateData: map[string]interface{}{"Branch": branch, "Error": err},
+					}))
+				}
+			}
+			// git usually drops the section itself, a failure just means there was none left
+			if !*keepConfigFlag && !branchInfos[branch].Remote {
+				if newCommand("git", "config", "--remove-section", "branch."+branch).Run() == nil {
+					summary.ConfigSectionsRemoved++
+				}
+			}
+			if *archiveFlag {
+				archiveTags = append(archiveTags, archiveTagName(branch))
+			}
+		}
+
+		// Branches without an upstream on a remote have nothing to delete remotely
+		if info := branchInfos[branch]; err == nil && *remoteFlag && info.HasRemoteUpstream() {
+			remoteDeletions = append(remoteDeletions, remoteDeletion{Branch: branch, Info: info})
 		}
 	}
+
+	// Whatever comes after the local deletions is not started once interrupted
+	stopped := interrupted.Load()
+	if stopped {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionInterrupted"}))
+	}
+
+	// A partial failure can be rolled back to the state before the run, an interrupted run counts as one
+	if (len(summary.Failed) > 0 || stopped) && len(summary.Deleted) > 0 {
+		rollback := *atomicFlag
+		if !rollback && askForce && !stopped {
+			prompt := &survey.Confirm{
+				Message: localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "RollbackPrompt",
+					TemplateData: map[string]interface{}{"Count": len(summary.Deleted)},
+				}),
+			}
+			survey.AskOne(prompt, &rollback, surveyStdio...)
+		}
+		if rollback {
+			// The backups also hold the upstream configuration, the recorded tips do without it
+			entries := make(map[string]backupEntry)
+			for _, info := range deletedBranches {
+				ref := "refs/heads/" + info.Name
+				if info.Remote {
+					ref = "refs/remotes/" + info.Name
+				}
+				entries[info.Name] = backupEntry{Name: info.Name, Ref: ref, Hash: info.Hash}
+			}
+			if sessions, err := listBackupSessions(); err == nil {
+				for _, session := range sessions {
+					if session.ID != backupSession {
+						continue
+					}
+					for _, entry := range session.Entries {
+						if _, ok := entries[entry.Name]; ok {
+							entries[entry.Name] = entry
+						}
+					}
+				}
+			}
+
+			var stillDeleted []BranchInfo
+			for _, info := range deletedBranches {
+				if err := restoreBackup(backupSession, entries[info.Name]); err != nil {
+					fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+						MessageID:    "ErrorRestoringBranch",
+						TemplateData: map[string]interface{}{"Branch": info.Name, "Error": err},
+					}))
+					summary.RestoreFailed = append(summary.RestoreFailed, info.Name)
+					stillDeleted = append(stillDeleted, info)
+					continue
+				}
+				if *archiveFlag {
+					deleteArchiveTag(info.Name)
+					archiveTags = slices.DeleteFunc(archiveTags, func(tag string) bool { return tag == archiveTagName(info.Name) })
+				}
+				if name, ok := trashed[info.Name]; ok {
+					dropTrash(name)
+					delete(trashed, info.Name)
+				}
+				summary.Restored = append(summary.Restored, info.Name)
+			}
+			deletedBranches = stillDeleted
+			summary.Deleted = nil
+			for _, info := range deletedBranches {
+				summary.Deleted = append(summary.Deleted, info.Name)
+			}
+			// Remote branches of restored branches stay where they are
+			remoteDeletions = slices.DeleteFunc(remoteDeletions, func(d remoteDeletion) bool {
+				return slices.Contains(summary.Restored, d.Branch)
+			})
+		}
+	}
+
+	// Stashes of branches that are still there, e.g. after a failure or rollback, are kept
+	if *dropStashesFlag && !stopped {
+		var deletedNames []string
+		for _, info := range deletedBranches {
+			deletedNames = append(deletedNames, info.Name)
+		}
+		var toDrop []stashEntry
+		for _, stash := range stashes {
+			if slices.Contains(deletedNames, stash.Branch) {
+				toDrop = append(toDrop, stash)
+			}
+		}
+		drop := len(toDrop) > 0 && *yesFlag
+		if len(toDrop) > 0 && askForce {
+			prompt := &survey.Confirm{
+				Message: localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "DropStashesPrompt",
+					TemplateData: map[string]interface{}{"Count": len(toDrop)},
+				}),
+			}
+			survey.AskOne(prompt, &drop, surveyStdio...)
+		}
+		if drop {
+			results := dropStashes(toDrop)
+			for _, stash := range toDrop {
+				if err := results[stash.Hash]; err != nil {
+					fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+						MessageID:    "ErrorDroppingStash",
+						TemplateData: map[string]interface{}{"Stash": stash.Message, "Error": err},
+					}))
+					continue
+				}
+				// Printed even with --quiet, as the hash is the only way to get the stash back
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "StashDropped",
+					TemplateData: map[string]interface{}{"Stash": stash.Message, "Hash": stash.Hash},
+				}))
+			}
+		}
+	}
+
+	// Pushes are slow, so they run concurrently and are reported in order once all are done
+	if !stopped && deleteRemoteBranches(remoteDeletions, *archiveFlag, *remoteJobsFlag, interactive) {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RemoteDeletionInterrupted"}))
+	}
+	for _, d := range remoteDeletions {
+		remoteName := d.Info.UpstreamRemote + "/" + d.Info.UpstreamBranch
+		if !d.Attempted {
+			summary.RemoteSkipped = append(summary.RemoteSkipped, remoteName)
+			continue
+		}
+		if d.AuthRequired {
+			// Without a terminal the credentials could not be asked for
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "RemoteAuthenticationRequired",
+				TemplateData: map[string]interface{}{"Remote": d.Info.UpstreamRemote, "Branch": d.Info.UpstreamBranch},
+			}))
+			writeJournal(d.Branch, d.Info.UpstreamRemote, false, errAuthRequired)
+			summary.RemoteFailed = append(summary.RemoteFailed, remoteName)
+			continue
+		}
+		if d.TagErr != nil {
+			summary.RemoteFailed = append(summary.RemoteFailed, remoteName)
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorPushingArchiveTag",
+				TemplateData: map[string]interface{}{"Remote": d.Info.UpstreamRemote, "Tag": archiveTagName(d.Branch), "Error": d.TagErr},
+			}))
+			fmt.Println(d.TagOutput)
+			// The remote branch was kept rather than lost without its tag
+			continue
+		}
+		writeJournal(d.Branch, d.Info.UpstreamRemote, false, d.Err)
+		templateData := map[string]interface{}{"Remote": d.Info.UpstreamRemote, "Branch": d.Info.UpstreamBranch, "Error": d.Err}
+		if d.Err != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "ErrorDeletingRemoteBranch", TemplateData: templateData}))
+			fmt.Println(d.Output)
+			summary.RemoteFailed = append(summary.RemoteFailed, remoteName)
+		} else {
+			if !*quietFlag {
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "RemoteBranchDeletedSuccessfully", TemplateData: templateData}))
+				fmt.Println(d.Output)
+			}
+			summary.RemoteDeleted = append(summary.RemoteDeleted, remoteName)
+		}
+	}
+
+	// Declined branches were kept on purpose
+	summary.Skipped = append(summary.Skipped, declinedBranches...)
+
+	if len(archiveTags) > 0 && !*quietFlag {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "ArchiveTagsCreated"}))
+		for _, tag := range archiveTags {
+			fmt.Println("  " + tag)
+		}
+	}
+
+	if len(trashed) > 0 && !*quietFlag {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "BranchesTrashed"}))
+		for _, info := range deletedBranches {
+			if name, ok := trashed[info.Name]; ok {
+				fmt.Println("  " + trashRefPrefix + name)
+			}
+		}
+	}
+
+	if !*noBackupFlag && len(deletedBranches) > 0 && !*quietFlag {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "BackupsCreated",
+			TemplateData: map[string]interface{}{"Prefix": backupRefPrefix + backupSession + "/"},
+		}))
+	}
+
+	// Tracking refs of branches already removed on the server linger until the next prune fetch
+	if (*pruneTrackingFlag || askForce) && !stopped {
+		staleRefs := findStaleTrackingRefs(deletedBranches)
+		prune := *pruneTrackingFlag
+		if len(staleRefs) > 0 && !prune {
+			prompt := &survey.Confirm{
+				Message: localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "PruneTrackingPrompt",
+					TemplateData: map[string]interface{}{"Refs": strings.Join(staleRefs, ", ")},
+				}),
+			}
+			survey.AskOne(prompt, &prune, surveyStdio...)
+		}
+		if len(staleRefs) > 0 && prune {
+			if !*quietFlag {
+				fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "PrunedTrackingRefs"}))
+			}
+			for _, ref := range staleRefs {
+				if output, err := newCommand("git", "branch", "-rd", ref).CombinedOutput(); err != nil {
+					fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+						MessageID:    "ErrorDeletingBranch",
+						TemplateData: map[string]interface{}{"Branch": ref, "Error": err},
+					}))
+					fmt.Println(string(output))
+				} else if !*quietFlag {
+					fmt.Println("  " + ref)
+				}
+			}
+		}
+	}
+
+	summary.Print(localizer, *remoteFlag)
+	return summary.ExitCode(interrupted.Load())
 }
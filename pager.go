@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+)
+
+// Lines kept free below a table for the warnings and the confirmation prompt
+const pagerReservedLines = 6
+
+// pagerCommand returns the shell command tables are paged with: $PAGER, else less when it is
+// installed. An empty $PAGER turns paging off, as it does for git.
+func pagerCommand() string {
+	if pager, ok := os.LookupEnv("PAGER"); ok {
+		return pager
+	}
+	if _, err := exec.LookPath("less"); err == nil {
+		// Keep the colors and do not wrap the wide rows
+		return "less -R -S"
+	}
+	return ""
+}
+
+// printPaged prints text, through the pager when it does not fit on the terminal. It reports
+// whether the pager was used, in which case the text is no longer on the screen.
+func printPaged(text []byte) bool {
+	height := terminalHeight()
+	pager := pagerCommand()
+	if !isTerminal(os.Stdout) || height == 0 || pager == "" || bytes.Count(text, []byte("\n")) <= height-pagerReservedLines {
+		os.Stdout.Write(text)
+		return false
+	}
+	// The pager reads its keys from the terminal, so the prompt after it still gets stdin
+	cmd := newCommand("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.Stdout.Write(text)
+		return false
+	}
+	return true
+}
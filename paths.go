@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// findBranchesTouchingPaths returns the branches with at least one commit in base..branch that
+// touches one of the paths. progress is called after each branch has been checked.
+func findBranchesTouchingPaths(base string, branches []string, paths []string, progress func(done, total int)) map[string]bool {
+	var mu sync.Mutex
+	touching := make(map[string]bool)
+	done := 0
+	runConcurrently(len(branches), func(i int) {
+		args := append([]string{"log", "-1", "--format=%H", base + ".." + branches[i], "--"}, paths...)
+		output, err := newCommand("git", args...).Output()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err == nil && strings.TrimSpace(string(output)) != "" {
+			touching[branches[i]] = true
+		}
+		done++
+		progress(done, len(branches))
+	})
+	return touching
+}
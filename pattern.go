@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// patternError reports a pattern that could not be compiled
+type patternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e *patternError) Error() string {
+	return fmt.Sprintf("invalid pattern %q: %v", e.Pattern, e.Err)
+}
+
+// branchMatcher matches branch names against glob patterns or, in regex mode, RE2 regular expressions
+type branchMatcher struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// newBranchMatcher validates the patterns and returns a matcher for them
+func newBranchMatcher(patterns []string, useRegex bool) (*branchMatcher, error) {
+	m := &branchMatcher{}
+	for _, pattern := range patterns {
+		if useRegex {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, &patternError{Pattern: pattern, Err: err}
+			}
+			m.regexps = append(m.regexps, re)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, &patternError{Pattern: pattern, Err: err}
+		}
+		m.globs = append(m.globs, pattern)
+	}
+	return m, nil
+}
+
+// Empty reports whether the matcher has no patterns
+func (m *branchMatcher) Empty() bool {
+	return len(m.globs) == 0 && len(m.regexps) == 0
+}
+
+// Match reports whether the branch name matches at least one of the patterns.
+// Regular expressions are not anchored, so they match anywhere in the name.
+func (m *branchMatcher) Match(branchName string) bool {
+	for _, pattern := range m.globs {
+		if matched, _ := path.Match(pattern, branchName); matched {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(branchName) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGlobMeta reports whether the pattern contains a glob metacharacter
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
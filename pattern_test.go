@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBranchMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		useRegex bool
+		branch   string
+		want     bool
+	}{
+		{"glob with slash", []string{"feature/*"}, false, "feature/login", true},
+		{"glob star does not cross slash", []string{"feature/*"}, false, "feature/team/login", false},
+		{"glob needs the prefix", []string{"feature/*"}, false, "bugfix/login", false},
+		{"glob over two levels", []string{"feature/*/*"}, false, "feature/team/login", true},
+		{"glob question mark", []string{"release/v?"}, false, "release/v2", true},
+		{"glob unicode name", []string{"機能/*"}, false, "機能/ログイン", true},
+		{"glob question mark matches one rune", []string{"fix-?"}, false, "fix-é", true},
+		{"glob exact unicode name", []string{"café"}, false, "café", true},
+		{"glob any of several", []string{"hotfix/*", "feature/*"}, false, "feature/x", true},
+		{"regex with slash", []string{"^feature/"}, true, "feature/login", true},
+		{"regex is not anchored", []string{"ABC-[0-9]+"}, true, "feature/ABC-123-login", true},
+		{"regex anchor", []string{"^ABC-[0-9]+"}, true, "feature/ABC-123-login", false},
+		{"regex unicode name", []string{"^機能/.+$"}, true, "機能/ログイン", true},
+		{"regex dot matches one rune", []string{"^fix-.$"}, true, "fix-é", true},
+		{"regex unicode class", []string{`^\p{Han}+/`}, true, "機能/ログイン", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newBranchMatcher(tt.patterns, tt.useRegex)
+			if err != nil {
+				t.Fatalf("newBranchMatcher(%q, %v): %v", tt.patterns, tt.useRegex, err)
+			}
+			if got := m.Match(tt.branch); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.branch, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewBranchMatcherInvalid(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		useRegex bool
+	}{
+		{"feature/[", false},
+		{"feature/(", true},
+		{"[z-a]", true},
+	}
+	for _, tt := range tests {
+		_, err := newBranchMatcher([]string{tt.pattern}, tt.useRegex)
+		var patternErr *patternError
+		if !errors.As(err, &patternErr) || patternErr.Pattern != tt.pattern {
+			t.Errorf("newBranchMatcher(%q, %v) error = %v, want a patternError for it", tt.pattern, tt.useRegex, err)
+		}
+	}
+}
+
+func TestBranchMatcherEmpty(t *testing.T) {
+	m, err := newBranchMatcher(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Empty() {
+		t.Error("matcher without patterns is not empty")
+	}
+	if m.Match("main") {
+		t.Error("matcher without patterns matched main")
+	}
+}
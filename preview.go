@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// defaultPreviewLimit is how many commits the preview lists below the diffstat
+const defaultPreviewLimit = 30
+
+// previewLogArgs returns the git log arguments of the preview. The "oneline" format draws the
+// commits of the branch that are not on the base as a graph, "full" is the plain git log of the
+// branch (only the commits not on --base when given) and anything else is a git log --format.
+// Without a base every format shows the whole history. A limit of 0 shows all commits.
+func previewLogArgs(branch, base, format string, limit int) []string {
+	args := []string{"log", gitColorOption()}
+	switch format {
+	case "oneline", "":
+		args = append(args, "--oneline", "--graph", "--decorate")
+	case "full":
+	default:
+		args = append(args, "--format="+format)
+	}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	revision := branch
+	if base != "" && base != branch {
+		// Only show the commits that are not yet on the base
+		revision = base + ".." + branch
+	}
+	return append(args, revision, "--")
+}
+
+// Preview modes ctrl-o cycles through. The summary is the diffstat followed by the log.
+var previewModes = []string{"summary", "log", "stat", "files"}
+
+// readPreviewMode returns the preview mode stored in the file, or the first mode
+func readPreviewMode(path string) string {
+	data, err := os.ReadFile(path)
+	if mode := strings.TrimSpace(string(data)); err == nil && slices.Contains(previewModes, mode) {
+		return mode
+	}
+	return previewModes[0]
+}
+
+// cyclePreviewMode stores the preview mode after the one in the file
+func cyclePreviewMode(path string) error {
+	i := slices.Index(previewModes, readPreviewMode(path))
+	return os.WriteFile(path, []byte(previewModes[(i+1)%len(previewModes)]), 0o600)
+}
+
+// previewDiffOption returns the git diff option of the preview mode, or "" for the log only
+func previewDiffOption(mode string) string {
+	switch mode {
+	case "summary", "stat":
+		return "--stat"
+	case "files":
+		return "--name-status"
+	}
+	return ""
+}
+
+// previewShowsLog reports whether the preview mode lists the commits
+func previewShowsLog(mode string) bool {
+	return mode == "summary" || mode == "log"
+}
+
+// previewDiffArgs returns the git diff arguments of the preview with the option of the mode.
+// The diffstat is fitted to the width of the pane when fzf tells it in columns.
+func previewDiffArgs(branch, base, option, columns string) []string {
+	if columns != "" && option == "--stat" {
+		option += "=" + columns
+	}
+	return []string{"diff", gitColorOption(), option, base + "..." + branch, "--"}
+}
+
+// runPreview prints the fzf preview of a branch in the given mode below its header. The summary
+// shows what the branch changes relative to the base followed by its most recent commits, the
+// other modes only the log, the diffstat or the changed files. Without --base the default branch
+// is the base, except that the full format keeps showing the whole log then.
+func runPreview(localizer *i18n.Localizer, branch, base, format string, limit int, mode string) {
+	diffBase := base
+	if diffBase == "" {
+		diffBase = getSwitchTarget("")
+	}
+
+	fmt.Println(ColorDim + localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "PreviewModeHeader",
+		TemplateData: map[string]interface{}{"Mode": mode},
+	}) + ColorReset)
+	printPreviewHeader(localizer, branch, diffBase)
+
+	if option := previewDiffOption(mode); option != "" {
+		printPreviewDiff(localizer, branch, diffBase, option)
+	}
+	if !previewShowsLog(mode) {
+		os.Exit(0)
+	}
+
+	logBase := diffBase
+	if format == "full" {
+		logBase = base
+	}
+	output, err := newCommand("git", previewLogArgs(branch, logBase, format, limit)...).Output()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting log for %s: %v\n", branch, err)
+		os.Exit(1)
+	}
+	if logBase != "" && len(output) == 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "NoCommitsAheadOfBase",
+			TemplateData: map[string]interface{}{"Base": logBase},
+		}))
+	}
+	os.Stdout.Write(output)
+	os.Exit(0)
+}
+
+// printPreviewDiff prints git diff with the option (--stat or --name-status) of the branch
+// against its merge base with base, or that there is no merge base
+func printPreviewDiff(localizer *i18n.Localizer, branch, base, option string) {
+	if base == "" || base == branch {
+		return
+	}
+	if newCommand("git", "merge-base", base, branch).Run() != nil {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "NoMergeBase",
+			TemplateData: map[string]interface{}{"Base": base},
+		}))
+		return
+	}
+	// fzf tells the width of the preview pane, so the stat lines are not wrapped
+	output, err := newCommand("git", previewDiffArgs(branch, base, option, os.Getenv("FZF_PREVIEW_COLUMNS"))...).Output()
+	if err == nil && len(strings.TrimSpace(string(output))) > 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "PreviewChangesHeader",
+			TemplateData: map[string]interface{}{"Base": base},
+		}))
+		os.Stdout.Write(output)
+		fmt.Println()
+	}
+}
+
+// printPreviewHeader prints the upstream of the branch with its ahead/behind counts,
+// how far the branch is ahead of and behind the base, and its tip commit.
+// It runs on every cursor move, so it sticks to one for-each-ref and one rev-list.
+func printPreviewHeader(localizer *i18n.Localizer, branch, base string) {
+	output, err := newCommand("git", "for-each-ref",
+		"--format=%(refname)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(objectname:short)%00%(committerdate:short)",
+		"refs/heads/"+branch, "refs/remotes/"+branch).Output()
+	if err != nil {
+		return
+	}
+	var fields []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// The patterns also match branches below the name, e.g. feature/foo/bar for feature/foo
+		if parts := strings.Split(line, "\x00"); len(parts) == 5 && (parts[0] == "refs/heads/"+branch || parts[0] == "refs/remotes/"+branch) {
+			fields = parts
+			break
+		}
+	}
+	if fields == nil {
+		return
+	}
+	upstream, track, hash, date := fields[1], fields[2], fields[3], fields[4]
+
+	switch {
+	case strings.HasPrefix(fields[0], "refs/remotes/"):
+		// Remote-tracking branches have no upstream of their own
+	case upstream == "":
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "PreviewNoUpstream"}))
+	case track == "gone":
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "PreviewUpstreamGone",
+			TemplateData: map[string]interface{}{"Upstream": upstream},
+		}))
+	default:
+		ahead, behind := 0, 0
+		// track reads like "ahead 3, behind 2", or is empty when both are level
+		for _, part := range strings.Split(track, ", ") {
+			fmt.Sscanf(part, "ahead %d", &ahead)
+			fmt.Sscanf(part, "behind %d", &behind)
+		}
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "PreviewUpstream",
+			TemplateData: map[string]interface{}{"Upstream": upstream, "Ahead": ahead, "Behind": behind},
+		}))
+	}
+
+	if base != "" && base != branch {
+		counts, err := newCommand("git", "rev-list", "--left-right", "--count", branch+"..."+base, "--").Output()
+		var ahead, behind int
+		if err == nil {
+			if _, err := fmt.Sscanf(string(counts), "%d %d", &ahead, &behind); err == nil {
+				// The preview cannot tell squash merges, so only none counts as safe
+				color := uniqueCommitsColor(ahead, false)
+				fmt.Println(color + localizer.MustLocalize(&i18n.LocalizeConfig{
+					MessageID:    "PreviewBaseCounts",
+					TemplateData: map[string]interface{}{"Base": base, "Ahead": ahead, "Behind": behind},
+				}) + ColorReset)
+			}
+		}
+	}
+
+	fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "PreviewTip",
+		TemplateData: map[string]interface{}{"Hash": hash, "Date": date},
+	}))
+	fmt.Println()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// withColors runs the test with colorsEnabled set as given and restores it afterwards
+func withColors(t *testing.T, enabled bool) {
+	t.Helper()
+	saved := colorsEnabled
+	colorsEnabled = enabled
+	t.Cleanup(func() { colorsEnabled = saved })
+}
+
+func TestPreviewLogArgs(t *testing.T) {
+	withColors(t, true)
+	tests := []struct {
+		name   string
+		branch string
+		base   string
+		format string
+		limit  int
+		want   []string
+	}{
+		{"oneline against the base", "feature/x", "main", "oneline", 30,
+			[]string{"log", "--color=always", "--oneline", "--graph", "--decorate", "-n", "30", "main..feature/x", "--"}},
+		{"empty format is oneline", "feature/x", "main", "", 30,
+			[]string{"log", "--color=always", "--oneline", "--graph", "--decorate", "-n", "30", "main..feature/x", "--"}},
+		{"full without a base", "feature/x", "", "full", 30,
+			[]string{"log", "--color=always", "-n", "30", "feature/x", "--"}},
+		{"custom format without a limit", "feature/x", "main", "%h %s", 0,
+			[]string{"log", "--color=always", "--format=%h %s", "main..feature/x", "--"}},
+		{"branch is the base", "main", "main", "oneline", 5,
+			[]string{"log", "--color=always", "--oneline", "--graph", "--decorate", "-n", "5", "main", "--"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := previewLogArgs(tt.branch, tt.base, tt.format, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("previewLogArgs(%q, %q, %q, %d) = %q, want %q", tt.branch, tt.base, tt.format, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreviewLogArgsWithoutColors(t *testing.T) {
+	withColors(t, false)
+	got := previewLogArgs("feature/x", "main", "oneline", 0)
+	want := []string{"log", "--color=never", "--oneline", "--graph", "--decorate", "main..feature/x", "--"}
+	if !slices.Equal(got, want) {
+		t.Errorf("previewLogArgs without colors = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewModeArgs(t *testing.T) {
+	withColors(t, true)
+	tests := []struct {
+		mode     string
+		showsLog bool
+		diff     []string
+	}{
+		{"summary", true, []string{"diff", "--color=always", "--stat=80", "main...feature/x", "--"}},
+		{"log", true, nil},
+		{"stat", false, []string{"diff", "--color=always", "--stat=80", "main...feature/x", "--"}},
+		{"files", false, []string{"diff", "--color=always", "--name-status", "main...feature/x", "--"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if got := previewShowsLog(tt.mode); got != tt.showsLog {
+				t.Errorf("previewShowsLog(%q) = %v, want %v", tt.mode, got, tt.showsLog)
+			}
+			option := previewDiffOption(tt.mode)
+			if tt.diff == nil {
+				if option != "" {
+					t.Errorf("previewDiffOption(%q) = %q, want none", tt.mode, option)
+				}
+				return
+			}
+			if got := previewDiffArgs("feature/x", "main", option, "80"); !slices.Equal(got, tt.diff) {
+				t.Errorf("diff args of %q = %q, want %q", tt.mode, got, tt.diff)
+			}
+		})
+	}
+	if !slices.Equal(previewModes, []string{"summary", "log", "stat", "files"}) {
+		t.Errorf("previewModes = %q, the test covers summary, log, stat and files", previewModes)
+	}
+}
+
+func TestPreviewDiffArgsWithoutColumns(t *testing.T) {
+	withColors(t, true)
+	got := previewDiffArgs("feature/x", "main", "--stat", "")
+	want := []string{"diff", "--color=always", "--stat", "main...feature/x", "--"}
+	if !slices.Equal(got, want) {
+		t.Errorf("previewDiffArgs without columns = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// askChoice asks a [y/N/all/quit] question and returns "y", "n", "all" or "quit".
+// Anything unrecognized counts as no, and an interrupted prompt as quit.
+func askChoice(message string, opts []survey.AskOpt) string {
+	prompt := &survey.Input{Message: message}
+	var answer string
+	if err := survey.AskOne(prompt, &answer, opts...); err != nil {
+		return "quit"
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return "y"
+	case "a", "all":
+		return "all"
+	case "q", "quit":
+		return "quit"
+	}
+	return "n"
+}
+
+// defaultConfirmThreshold is the number of selected branches above which the confirmation has to be typed
+const defaultConfirmThreshold = 10
+
+// askTypedConfirmation asks to type the number of branches or "delete", so a large
+// selection is not deleted by a stray key. Anything else declines.
+func askTypedConfirmation(message string, count int, opts []survey.AskOpt) bool {
+	prompt := &survey.Input{Message: message}
+	var answer string
+	if err := survey.AskOne(prompt, &answer, opts...); err != nil {
+		return false
+	}
+	answer = strings.TrimSpace(answer)
+	return answer == strconv.Itoa(count) || strings.EqualFold(answer, "delete")
+}
+
+// askDeclined asks what to do after the deletion was declined and returns "back" to go back to
+// the selection, "edit" to take branches off the list or "cancel". Going back is only offered
+// when canGoBack. An interrupted prompt cancels.
+func askDeclined(localizer *i18n.Localizer, canGoBack bool, opts []survey.AskOpt) string {
+	var choices, labels []string
+	if canGoBack {
+		choices = append(choices, "back")
+		labels = append(labels, localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeclinedBackToSelection"}))
+	}
+	choices = append(choices, "edit", "cancel")
+	labels = append(labels,
+		localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeclinedEditList"}),
+		localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeclinedCancel"}),
+	)
+	prompt := &survey.Select{
+		Message: localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeclinedPrompt"}),
+		Options: labels,
+	}
+	var index int
+	if err := survey.AskOne(prompt, &index, opts...); err != nil {
+		return "cancel"
+	}
+	return choices[index]
+}
+
+// parseRowNumbers parses row numbers from 1 to count such as "1 3,5-7" and returns them as
+// indexes from 0
+func parseRowNumbers(s string, count int) ([]int, error) {
+	var indexes []int
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		first, last, isRange := strings.Cut(word, "-")
+		if !isRange {
+			last = first
+		}
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a row number", word)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a row number", word)
+		}
+		if from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("%q is not within 1-%d", word, count)
+		}
+		for n := from; n <= to; n++ {
+			indexes = append(indexes, n-1)
+		}
+	}
+	return indexes, nil
+}
+
+// askRowNumbers asks for the numbers of rows from 1 to count until they parse and returns them
+// as indexes from 0. An interrupted prompt returns none.
+func askRowNumbers(message string, count int, opts []survey.AskOpt) []int {
+	prompt := &survey.Input{Message: message}
+	validate := func(answer interface{}) error {
+		_, err := parseRowNumbers(answer.(string), count)
+		return err
+	}
+	var answer string
+	if err := survey.AskOne(prompt, &answer, append(opts, survey.WithValidator(validate))...); err != nil {
+		return nil
+	}
+	indexes, _ := parseRowNumbers(answer, count)
+	return indexes
+}
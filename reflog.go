@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// getBranchCreationDate returns the date of the oldest reflog entry of the branch,
+// which is usually when it was created. ok is false when the branch has no reflog.
+func getBranchCreationDate(branch string) (created time.Time, ok bool) {
+	output, err := newCommand("git", "log", "-g", "--date=unix", "--format=%gd", branch, "--").Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	// Entries are listed newest first and look like "feature/foo@{1700000000}"
+	oldest := lines[len(lines)-1]
+	start := strings.LastIndex(oldest, "@{")
+	if start < 0 || !strings.HasSuffix(oldest, "}") {
+		return time.Time{}, false
+	}
+	timestamp, err := strconv.ParseInt(oldest[start+2:len(oldest)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(timestamp, 0), true
+}
+
+// getBranchCreationDates returns the creation date of every branch that has a reflog
+func getBranchCreationDates(branches []string) map[string]time.Time {
+	var mu sync.Mutex
+	dates := make(map[string]time.Time)
+	runConcurrently(len(branches), func(i int) {
+		if created, ok := getBranchCreationDate(branches[i]); ok {
+			mu.Lock()
+			dates[branches[i]] = created
+			mu.Unlock()
+		}
+	})
+	return dates
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// relativeDate describes how long ago t was, e.g. "7 months ago", in the largest fitting unit
+func relativeDate(localizer *i18n.Localizer, t, now time.Time) string {
+	age := now.Sub(t)
+	days := int(age.Hours() / 24)
+	var messageID string
+	var count int
+	switch {
+	case age < time.Minute:
+		return localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "JustNow"})
+	case age < time.Hour:
+		messageID, count = "MinutesAgo", int(age.Minutes())
+	case age < 24*time.Hour:
+		messageID, count = "HoursAgo", int(age.Hours())
+	case days < 14:
+		messageID, count = "DaysAgo", days
+	case days < 60:
+		messageID, count = "WeeksAgo", days/7
+	case days < 365:
+		messageID, count = "MonthsAgo", days/30
+	default:
+		messageID, count = "YearsAgo", days/365
+	}
+	return localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    messageID,
+		TemplateData: map[string]interface{}{"Count": count},
+		PluralCount:  count,
+	})
+}
+
+// Values of --date. "default" is git's own date format in the confirmation table and a
+// relative date in the list.
+var dateModes = []string{"default", "relative", "iso", "short"}
+
+// isValidDateMode reports whether mode is one of dateModes
+func isValidDateMode(mode string) bool {
+	return slices.Contains(dateModes, mode)
+}
+
+// formatDate renders t for the relative, iso and short modes of --date
+func formatDate(localizer *i18n.Localizer, t time.Time, mode string, now time.Time) string {
+	switch mode {
+	case "iso":
+		return t.Local().Format("2006-01-02 15:04")
+	case "short":
+		return t.Local().Format("2006-01-02")
+	}
+	return relativeDate(localizer, t, now)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+)
+
+// remoteDeletion is the deletion of the upstream branch of a deleted local branch
+type remoteDeletion struct {
+	// Branch is the local branch, Info holds its upstream
+	Branch string
+	Info   BranchInfo
+	// Whether the push was started at all, it is not after an interrupt
+	Attempted bool
+	// Result of pushing the archive tag, when archiving
+	TagOutput string
+	TagErr    error
+	Output    string
+	Err       error
+	// The push failed because credentials were needed and nobody could enter them
+	AuthRequired bool
+}
+
+// errAuthRequired is the error of pushes that needed credentials without a terminal to ask for them
+var errAuthRequired = errors.New("authentication required")
+
+// Messages git and ssh print when they could not ask for credentials
+var authFailureMessages = []string{
+	"terminal prompts disabled",
+	"could not read Username",
+	"could not read Password",
+	"Authentication failed",
+	"Permission denied (publickey",
+	"Host key verification failed",
+}
+
+// deleteRemoteBranches deletes the remote branches with one git push --delete per remote,
+// running at most workers remotes at once. With archive the archive tags are pushed first,
+// and the remote branches whose tag failed are kept.
+// Ctrl+C stops starting new pushes and reports whether that happened.
+// With terminal the pushes can ask for credentials, so they run one at a time to keep the prompts apart.
+func deleteRemoteBranches(deletions []remoteDeletion, archive bool, workers int, terminal bool) (interrupted bool) {
+	if terminal {
+		workers = 1
+	}
+	var stopped atomic.Bool
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupts:
+			stopped.Store(true)
+		case <-done:
+		}
+	}()
+
+	// Indexes of the deletions of each remote, in the order the remotes first appear
+	var remotes []string
+	byRemote := make(map[string][]int)
+	for i, d := range deletions {
+		remote := d.Info.UpstreamRemote
+		if byRemote[remote] == nil {
+			remotes = append(remotes, remote)
+		}
+		byRemote[remote] = append(byRemote[remote], i)
+	}
+
+	runConcurrentlyLimit(len(remotes), workers, func(r int) {
+		if stopped.Load() {
+			return
+		}
+		remote := remotes[r]
+		pending := byRemote[remote]
+		for _, i := range pending {
+			deletions[i].Attempted = true
+		}
+		if archive {
+			var tags []string
+			for _, i := range pending {
+				tags = append(tags, "refs/tags/"+archiveTagName(deletions[i].Branch))
+			}
+			results := pushRefs(remote, false, tags, tags, terminal)
+			var tagged []int
+			for n, i := range pending {
+				result := results[tags[n]]
+				deletions[i].TagOutput, deletions[i].TagErr = result.Output, result.Err
+				deletions[i].AuthRequired = result.Err == errAuthRequired
+				if result.Err == nil {
+					tagged = append(tagged, i)
+				}
+			}
+			pending = tagged
+		}
+		if len(pending) == 0 {
+			return
+		}
+		var branches, refs []string
+		for _, i := range pending {
+			branches = append(branches, deletions[i].Info.UpstreamBranch)
+			refs = append(refs, "refs/heads/"+deletions[i].Info.UpstreamBranch)
+		}
+		results := pushRefs(remote, true, branches, refs, terminal)
+		for n, i := range pending {
+			deletions[i].Output, deletions[i].Err = results[refs[n]].Output, results[refs[n]].Err
+			deletions[i].AuthRequired = results[refs[n]].Err == errAuthRequired
+		}
+	})
+	return stopped.Load()
+}
+
+// pushRefs pushes the refspecs to the remote in one git push, or deletes them with del.
+// refs are the remote refnames the refspecs update, the results are keyed by them.
+// The porcelain output tells the outcome of each ref, refs it does not mention are pushed again one by one.
+// With terminal git and ssh share the terminal for their prompts and messages, only the porcelain output is captured.
+func pushRefs(remote string, del bool, specs, refs []string, terminal bool) map[string]deleteResult {
+	args := []string{"push", "--porcelain", remote}
+	if del {
+		args = append(args, "--delete")
+	}
+	cmd := newCommand("git", append(args, specs...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if terminal {
+		cmd.Stdin, cmd.Stderr = os.Stdin, os.Stderr
+	} else {
+		cmd.Env = nonInteractivePushEnv()
+	}
+	err := cmd.Run()
+
+	results := make(map[string]deleteResult)
+	if err != nil && !terminal && isAuthFailure(stderr.String()) {
+		// No ref got through, and pushing them one by one would fail the same way
+		for _, ref := range refs {
+			results[ref] = deleteResult{Output: strings.TrimSpace(stderr.String()), Err: errAuthRequired}
+		}
+		return results
+	}
+	if len(specs) == 1 {
+		output := strings.TrimSpace(stderr.String() + "\n" + stdout.String())
+		results[refs[0]] = deleteResult{Output: output, Err: err}
+		return results
+	}
+
+	// Ref lines look like "-\t:refs/heads/foo\t[deleted]" or "!\t:refs/heads/foo\t[remote rejected] (protected branch)"
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		_, ref, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		result := deleteResult{Output: fields[2]}
+		if fields[0] == "!" {
+			// Messages of the remote, e.g. from its hooks, cannot be told apart by ref
+			result.Output = strings.TrimSpace(stderr.String())
+			result.Err = errors.New(fields[2])
+		}
+		results[ref] = result
+	}
+	for n, ref := range refs {
+		if _, ok := results[ref]; !ok {
+			results[ref] = pushRefs(remote, del, specs[n:n+1], refs[n:n+1], terminal)[ref]
+		}
+	}
+	return results
+}
+
+// nonInteractivePushEnv returns the environment of pushes that cannot prompt. git and ssh
+// would otherwise wait for credentials nobody can enter. A GIT_TERMINAL_PROMPT or ssh command
+// set by the user is kept, so CI setups decide for themselves.
+func nonInteractivePushEnv() []string {
+	env := os.Environ()
+	if _, ok := os.LookupEnv("GIT_TERMINAL_PROMPT"); !ok {
+		env = append(env, "GIT_TERMINAL_PROMPT=0")
+	}
+	_, sshSet := os.LookupEnv("GIT_SSH_COMMAND")
+	if !sshSet && newCommand("git", "config", "--get", "core.sshCommand").Run() != nil {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return env
+}
+
+// isAuthFailure reports whether the push output says credentials were needed
+func isAuthFailure(output string) bool {
+	for _, message := range authFailureMessages {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Operations finishing within spinnerDelay do not draw the spinner at all, so it does not flicker
+const spinnerDelay = 150 * time.Millisecond
+
+// spinnerFrames are drawn in turn in front of the spinner message
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// startSpinner draws a spinner with the message on stderr until the returned function is called.
+// Nothing is drawn when stdout or stderr is not a terminal, or when --verbose logs the git commands.
+// Nothing may be printed while the spinner runs, it would end up on the spinner line.
+func startSpinner(message string) (stop func()) {
+	if !isTerminal(os.Stderr) || !isTerminal(os.Stdout) || verboseCommands {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case <-done:
+			return
+		case <-time.After(spinnerDelay):
+		}
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s", spinnerFrames[i%len(spinnerFrames)], message)
+			select {
+			case <-done:
+				// Clear the spinner line
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
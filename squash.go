@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// isSquashMerged reports whether the changes of branch are already contained in base.
+// It builds a temporary commit with the branch's tree on top of the merge-base and asks
+// git cherry whether an equivalent patch already exists on base.
+func isSquashMerged(base, branch string) bool {
+	mergeBase, err := newCommand("git", "merge-base", base, branch).Output()
+	if err != nil {
+		return false
+	}
+	tree, err := newCommand("git", "rev-parse", branch+"^{tree}").Output()
+	if err != nil {
+		return false
+	}
+	commit, err := newCommand("git", "commit-tree", strings.TrimSpace(string(tree)),
+		"-p", strings.TrimSpace(string(mergeBase)), "-m", "git-delete-branch squash check").Output()
+	if err != nil {
+		return false
+	}
+	cherry, err := newCommand("git", "cherry", base, strings.TrimSpace(string(commit))).Output()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(cherry)), "-")
+}
+
+// detectSquashMergedBranches returns the subset of branches that were squash-merged into base
+func detectSquashMergedBranches(base string, branches []string) map[string]bool {
+	var mu sync.Mutex
+	squashMerged := make(map[string]bool)
+	runConcurrently(len(branches), func(i int) {
+		if isSquashMerged(base, branches[i]) {
+			mu.Lock()
+			squashMerged[branches[i]] = true
+			mu.Unlock()
+		}
+	})
+	return squashMerged
+}
+
+// isRebaseMerged reports whether every commit in base..branch has an equivalent patch on base
+func isRebaseMerged(base, branch string) bool {
+	output, err := newCommand("git", "cherry", base, branch).Output()
+	if err != nil {
+		return false
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines {
+		// "+" marks a commit without an equivalent on base
+		if !strings.HasPrefix(line, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// detectRebaseMergedBranches returns the subset of branches that were rebase-merged into base
+func detectRebaseMergedBranches(base string, branches []string) map[string]bool {
+	var mu sync.Mutex
+	rebaseMerged := make(map[string]bool)
+	runConcurrently(len(branches), func(i int) {
+		if isRebaseMerged(base, branches[i]) {
+			mu.Lock()
+			rebaseMerged[branches[i]] = true
+			mu.Unlock()
+		}
+	})
+	return rebaseMerged
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// stashEntry is one entry of git stash list
+type stashEntry struct {
+	// Index is n in stash@{n}, it changes whenever a newer stash is dropped
+	Index   int
+	Hash    string
+	Branch  string
+	Message string
+}
+
+// Ref returns the stash@{n} name of the entry
+func (e stashEntry) Ref() string {
+	return "stash@{" + strconv.Itoa(e.Index) + "}"
+}
+
+// parseStashBranch returns the branch a stash was made on, from its reflog subject.
+// git writes "WIP on <branch>: ..." or "On <branch>: <message>" for stashes with a message.
+// Anything else, e.g. a stash saved with git stash store -m, has no known branch.
+func parseStashBranch(subject string) (string, bool) {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "On ")
+	}
+	if !ok {
+		return "", false
+	}
+	// Branch names cannot contain a colon
+	branch, _, ok := strings.Cut(rest, ": ")
+	if !ok || branch == "" || branch == "(no branch)" {
+		return "", false
+	}
+	return branch, true
+}
+
+// getStashes returns the stash entries that were made on one of the branches, newest first
+func getStashes(branches []string) []stashEntry {
+	output, err := newCommand("git", "stash", "list", "--format=%H%x00%gs").Output()
+	if err != nil {
+		return nil
+	}
+	var entries []stashEntry
+	for i, line := range strings.Split(strings.TrimSuffix(string(output), "\n"), "\n") {
+		hash, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		branch, ok := parseStashBranch(subject)
+		if !ok || !slices.Contains(branches, branch) {
+			continue
+		}
+		entries = append(entries, stashEntry{Index: i, Hash: hash, Branch: branch, Message: subject})
+	}
+	return entries
+}
+
+// dropStashes drops the stash entries. The stash list is read again, so the entries are
+// found by their commit even if other stashes were pushed or dropped in the meantime.
+func dropStashes(entries []stashEntry) map[string]error {
+	results := make(map[string]error)
+	var hashes []string
+	for _, entry := range entries {
+		hashes = append(hashes, entry.Hash)
+		results[entry.Hash] = fmt.Errorf("stash %s no longer exists", entry.Hash[:min(8, len(entry.Hash))])
+	}
+	output, err := newCommand("git", "stash", "list", "--format=%H").Output()
+	if err != nil {
+		for hash := range results {
+			results[hash] = err
+		}
+		return results
+	}
+	current := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	// Dropping from the oldest entry keeps the indexes of the newer ones valid
+	for i := len(current) - 1; i >= 0; i-- {
+		if !slices.Contains(hashes, current[i]) {
+			continue
+		}
+		ref := stashEntry{Index: i}.Ref()
+		if output, err := newCommand("git", "stash", "drop", ref).CombinedOutput(); err != nil {
+			results[current[i]] = fmt.Errorf("%w\n%s", err, string(output))
+		} else {
+			results[current[i]] = nil
+		}
+	}
+	return results
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// runSummary collects the outcome of every selected branch for the end-of-run summary
+type runSummary struct {
+	Deleted []string
+	Failed  []string
+	// Skipped holds protected, current and declined branches
+	Skipped []string
+	// Remote results are only filled with --remote
+	RemoteDeleted []string
+	RemoteFailed  []string
+	RemoteSkipped []string
+	// Branches recreated after a partial failure, and those that could not be
+	Restored      []string
+	RestoreFailed []string
+	// Number of branch.<name> config sections left behind by git and removed afterwards
+	ConfigSectionsRemoved int
+}
+
+// Print prints the localized summary block
+func (s runSummary) Print(localizer *i18n.Localizer, remote bool) {
+	line := func(messageID string, branches []string) {
+		text := localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    messageID,
+			TemplateData: map[string]interface{}{"Count": len(branches)},
+		})
+		if len(branches) > 0 && messageID != "SummaryDeleted" && messageID != "SummaryRemoteDeleted" && messageID != "SummaryRestored" {
+			// Successful deletions were already reported one by one
+			text += " (" + strings.Join(branches, ", ") + ")"
+		}
+		fmt.Println("  " + text)
+	}
+
+	fmt.Println()
+	fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "SummaryHeader"}))
+	line("SummaryDeleted", s.Deleted)
+	line("SummaryFailed", s.Failed)
+	line("SummarySkipped", s.Skipped)
+	if len(s.Restored) > 0 || len(s.RestoreFailed) > 0 {
+		line("SummaryRestored", s.Restored)
+		line("SummaryRestoreFailed", s.RestoreFailed)
+	}
+	if s.ConfigSectionsRemoved > 0 {
+		fmt.Println("  " + localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "SummaryConfigSectionsRemoved",
+			TemplateData: map[string]interface{}{"Count": s.ConfigSectionsRemoved},
+		}))
+	}
+	if remote {
+		line("SummaryRemoteDeleted", s.RemoteDeleted)
+		line("SummaryRemoteFailed", s.RemoteFailed)
+		if len(s.RemoteSkipped) > 0 {
+			line("SummaryRemoteSkipped", s.RemoteSkipped)
+		}
+	}
+}
+
+// HasFailures reports whether a local or remote deletion failed
+func (s runSummary) HasFailures() bool {
+	return len(s.Failed) > 0 || len(s.RemoteFailed) > 0
+}
+
+// ExitCode returns the exit status of a run with this outcome: exitInterrupted after Ctrl+C,
+// 1 when any local or remote deletion failed, even if others succeeded, and 0 otherwise
+func (s runSummary) ExitCode(interrupted bool) int {
+	if interrupted {
+		return exitInterrupted
+	}
+	// Scripts rely on the exit status to notice failed deletions
+	if s.HasFailures() {
+		return 1
+	}
+	return 0
+}
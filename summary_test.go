@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestRunSummaryExitCode(t *testing.T) {
+	tests := []struct {
+		name        string
+		summary     runSummary
+		interrupted bool
+		want        int
+	}{
+		{"nothing selected", runSummary{}, false, 0},
+		{"all deleted", runSummary{Deleted: []string{"a", "b"}}, false, 0},
+		{"deleted and skipped", runSummary{Deleted: []string{"a"}, Skipped: []string{"main"}}, false, 0},
+		{"some deleted, some failed", runSummary{Deleted: []string{"a"}, Failed: []string{"b"}}, false, 1},
+		{"all failed", runSummary{Failed: []string{"a", "b"}}, false, 1},
+		{"local deleted, remote failed", runSummary{Deleted: []string{"a"}, RemoteFailed: []string{"origin/a"}}, false, 1},
+		{"remote skipped only", runSummary{Deleted: []string{"a"}, RemoteSkipped: []string{"origin/a"}}, false, 0},
+		{"interrupted", runSummary{Deleted: []string{"a"}}, true, exitInterrupted},
+		{"interrupted after a failure", runSummary{Deleted: []string{"a"}, Failed: []string{"b"}}, true, exitInterrupted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.ExitCode(tt.interrupted); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.interrupted, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Columns a cell is never shrunk below to fit the table into the terminal
+const minTableColumnWidth = 6
+
+// Columns the last column, usually the commit message, keeps before other columns are shrunk
+const minLastColumnWidth = 20
+
+// Width assumed when the output is not a terminal and COLUMNS is not set
+const fallbackTerminalWidth = 80
+
+// table lays out rows of cells in columns that are as wide as their widest cell, up to a maximum.
+// Cells may contain ANSI color codes, which take no columns.
+type table struct {
+	headers []string
+	// Maximum width of each column, 0 for no limit
+	maxWidths []int
+	rows      [][]string
+	// Width the whole table is fitted into, 0 for no limit
+	width int
+}
+
+// addColumn appends a column. Cells wider than maxWidth are cut with an ellipsis.
+func (t *table) addColumn(header string, maxWidth int) {
+	t.headers = append(t.headers, header)
+	t.maxWidths = append(t.maxWidths, maxWidth)
+}
+
+// addRow appends a row with one cell per column
+func (t *table) addRow(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+// widths returns the width of every column. When the table is wider than t.width, the last
+// column is shrunk first and then the widest of the others, one column at a time.
+func (t *table) widths() []int {
+	widths := make([]int, len(t.headers))
+	for _, row := range append([][]string{t.headers}, t.rows...) {
+		for i, cell := range row {
+			width := cellWidth(cell)
+			if t.maxWidths[i] > 0 {
+				width = min(width, t.maxWidths[i])
+			}
+			widths[i] = max(widths[i], width)
+		}
+	}
+	if t.width <= 0 || len(widths) == 0 {
+		return widths
+	}
+
+	excess := tableWidth(widths) - t.width
+	last := len(widths) - 1
+	if excess > 0 && widths[last] > minLastColumnWidth {
+		shrink := min(excess, widths[last]-minLastColumnWidth)
+		widths[last] -= shrink
+		excess -= shrink
+	}
+	for excess > 0 {
+		widest := -1
+		for i := 0; i < last; i++ {
+			if widths[i] > minTableColumnWidth && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			// Nothing left to shrink, so the rows wrap
+			break
+		}
+		widths[widest]--
+		excess--
+	}
+	return widths
+}
+
+// tableWidth returns the width of a table with the given columns separated by single spaces
+func tableWidth(widths []int) int {
+	total := max(len(widths)-1, 0)
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
+
+// render writes the header, a separator line as wide as the table, the rows and a closing separator
+func (t *table) render(w io.Writer) {
+	widths := t.widths()
+	separator := strings.Repeat("-", tableWidth(widths))
+
+	t.renderRow(w, t.headers, widths)
+	fmt.Fprintln(w, separator)
+	for _, row := range t.rows {
+		t.renderRow(w, row, widths)
+	}
+	fmt.Fprintln(w, separator)
+}
+
+// renderRow writes one line, cutting every cell to its column width and padding all but the last
+func (t *table) renderRow(w io.Writer, cells []string, widths []int) {
+	var b strings.Builder
+	for i, cell := range cells {
+		cell = truncateCell(cell, widths[i])
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(cell)
+		if i < len(cells)-1 {
+			b.WriteString(strings.Repeat(" ", widths[i]-cellWidth(cell)))
+		}
+	}
+	fmt.Fprintln(w, b.String())
+}
+
+// cellWidth returns the number of terminal columns the cell occupies, ignoring color codes
+func cellWidth(cell string) int {
+	return displayWidth(ansiStripper.ReplaceAllString(cell, ""))
+}
+
+// truncateCell is truncateWidth for cells with color codes, which are kept and reset after the ellipsis
+func truncateCell(cell string, width int) string {
+	if cellWidth(cell) <= width {
+		return cell
+	}
+	if !strings.Contains(cell, "\033") {
+		return truncateWidth(cell, width)
+	}
+	var b strings.Builder
+	used := 0
+	rest := cell
+	for rest != "" {
+		if loc := ansiStripper.FindStringIndex(rest); loc != nil && loc[0] == 0 {
+			b.WriteString(rest[:loc[1]])
+			rest = rest[loc[1]:]
+			continue
+		}
+		r := []rune(rest)[0]
+		if used+runeWidth(r) > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+		rest = rest[len(string(r)):]
+	}
+	return b.String() + "…" + ColorReset
+}
+
+// tableWidthLimit returns the width tables are fitted into: the terminal width, else $COLUMNS,
+// else a fallback of 80 columns
+func tableWidthLimit() int {
+	if width := terminalWidth(); width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return fallbackTerminalWidth
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// narrowTable returns a confirmation-like table fitted into width columns
+func narrowTable(width int) *table {
+	t := &table{width: width}
+	t.addColumn("Branch", 40)
+	t.addColumn("Status", 24)
+	t.addColumn("Message", 0)
+	t.addRow("feature/login-page", "merged", "Add the login page with a form, validation and remember-me support")
+	t.addRow("fix/typo", "unmerged", "Fix typo")
+	return t
+}
+
+func TestTableWidths(t *testing.T) {
+	tests := []struct {
+		name  string
+		width int
+		want  []int
+	}{
+		// 18 + 8 + 66 and two separators
+		{"no limit", 0, []int{18, 8, 66}},
+		{"wide enough", 120, []int{18, 8, 66}},
+		// Only the message is shrunk, it keeps more than its minimum
+		{"80 columns", 80, []int{18, 8, 52}},
+		// The message stops at its minimum, then the widest other column is shrunk
+		{"40 columns", 40, []int{10, 8, 20}},
+		// Every column is at its minimum and the rows wrap
+		{"too narrow", 20, []int{minTableColumnWidth, minTableColumnWidth, minLastColumnWidth}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := narrowTable(tt.width).widths()
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("widths() at %d columns = %v, want %v", tt.width, got, tt.want)
+			}
+			if tt.width >= tableWidth(tt.want) && tableWidth(got) > tt.width {
+				t.Errorf("table is %d columns wide, more than %d", tableWidth(got), tt.width)
+			}
+		})
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	tests := []struct {
+		width int
+		want  string
+	}{
+		{80, "" +
+			"Branch             Status   Message\n" +
+			strings.Repeat("-", 80) + "\n" +
+			"feature/login-page merged   Add the login page with a form, validation and reme…\n" +
+			"fix/typo           unmerged Fix typo\n" +
+			strings.Repeat("-", 80) + "\n"},
+		{40, "" +
+			"Branch     Status   Message\n" +
+			strings.Repeat("-", 40) + "\n" +
+			"feature/l… merged   Add the login page …\n" +
+			"fix/typo   unmerged Fix typo\n" +
+			strings.Repeat("-", 40) + "\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		narrowTable(tt.width).render(&out)
+		if out.String() != tt.want {
+			t.Errorf("render() at %d columns =\n%s\nwant\n%s", tt.width, out.String(), tt.want)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+			if width := displayWidth(line); width > tt.width {
+				t.Errorf("line %q is %d columns wide, more than %d", line, width, tt.width)
+			}
+		}
+	}
+}
+
+func TestTruncateCell(t *testing.T) {
+	withColors(t, true)
+	tests := []struct {
+		name  string
+		cell  string
+		width int
+		want  string
+	}{
+		{"fits", "merged", 6, "merged"},
+		{"plain cut", "feature/login-page", 10, "feature/l…"},
+		{"colors are kept", "\033[31mfeature/login-page\033[0m", 10, "\033[31mfeature/l…" + ColorReset},
+		{"colors do not count", "\033[32mmerged\033[0m", 6, "\033[32mmerged\033[0m"},
+		{"wide characters", "\033[31m機能/ログイン\033[0m", 8, "\033[31m機能/ロ…" + ColorReset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateCell(tt.cell, tt.width)
+			if got != tt.want {
+				t.Errorf("truncateCell(%q, %d) = %q, want %q", tt.cell, tt.width, got, tt.want)
+			}
+			if width := cellWidth(got); width > tt.width {
+				t.Errorf("truncateCell(%q, %d) is %d columns wide", tt.cell, tt.width, width)
+			}
+		})
+	}
+}
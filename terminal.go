@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// isTerminal reports whether the file is connected to a terminal
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// terminalWidth returns the width of the terminal fzf and the tables are drawn on, or 0 if unknown
+func terminalWidth() int {
+	for _, f := range []*os.File{os.Stderr, os.Stdout, os.Stdin} {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	return 0
+}
+
+// terminalHeight returns the number of lines of the terminal stdout is on, or 0 if unknown
+func terminalHeight() int {
+	if _, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		return height
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// colorConfigSection holds the per-role color overrides, e.g. delete-branch.color.merged
+const colorConfigSection = configSection + "color."
+
+// Roles a theme assigns a color to, in the order they are documented
+var themeRoles = []string{"merged", "unmerged", "warning", "dim", "accent", "remote", "gone"}
+
+// themePresets are the themes --theme selects. Every role is a color spec for ansiCode.
+var themePresets = map[string]map[string]string{
+	"default": {"merged": "green", "unmerged": "red", "warning": "red", "dim": "dim", "accent": "magenta", "remote": "cyan", "gone": "yellow"},
+	// Dark yellow and green are hard to read on a light background
+	"light": {"merged": "blue", "unmerged": "red", "warning": "bold red", "dim": "bright-black", "accent": "magenta", "remote": "bold cyan", "gone": "bold yellow"},
+	"mono":  {"merged": "", "unmerged": "bold", "warning": "bold", "dim": "dim", "accent": "bold", "remote": "underline", "gone": "italic"},
+}
+
+// themeColorSpecs holds the color spec applyTheme gave every role, for naming the colors
+var themeColorSpecs = maps.Clone(themePresets["default"])
+
+// SGR parameters of the basic colors, bright-<color> adds 60
+var colorCodes = map[string]int{
+	"black": 30, "red": 31, "green": 32, "yellow": 33, "blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+}
+
+// ansiCode turns a color spec such as "cyan", "bold red" or "bright-black" into its escape code.
+// Words are separated by spaces, commas or "+". An empty spec or "none" means no color.
+func ansiCode(spec string) (string, error) {
+	var params []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(spec), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '+'
+	}) {
+		switch {
+		case word == "none" || word == "default":
+		case word == "bold":
+			params = append(params, "1")
+		case word == "dim":
+			params = append(params, "2")
+		case word == "italic":
+			params = append(params, "3")
+		case word == "underline":
+			params = append(params, "4")
+		case strings.HasPrefix(word, "bright-") && colorCodes[strings.TrimPrefix(word, "bright-")] != 0:
+			params = append(params, fmt.Sprint(colorCodes[strings.TrimPrefix(word, "bright-")]+60))
+		case colorCodes[word] != 0:
+			params = append(params, fmt.Sprint(colorCodes[word]))
+		default:
+			return "", fmt.Errorf("unknown color %q", word)
+		}
+	}
+	if len(params) == 0 {
+		return "", nil
+	}
+	return "\033[" + strings.Join(params, ";") + "m", nil
+}
+
+// applyTheme sets the colors of one of themePresets, then the delete-branch.color.<role>
+// overrides from the git config. It returns the overrides that were invalid, which are ignored.
+func applyTheme(preset string) (invalid []string) {
+	codes := make(map[string]string)
+	for role, spec := range themePresets[preset] {
+		codes[role], _ = ansiCode(spec)
+		themeColorSpecs[role] = spec
+	}
+
+	output, err := newCommand("git", "config", "--get-regexp", `^delete-branch\.color\.`).Output()
+	if err == nil {
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			role := strings.TrimPrefix(key, colorConfigSection)
+			if !slices.Contains(themeRoles, role) {
+				invalid = append(invalid, fmt.Sprintf("%s: unknown role, expected one of %s", key, strings.Join(themeRoles, ", ")))
+				continue
+			}
+			code, err := ansiCode(value)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s=%q: %v", key, value, err))
+				continue
+			}
+			codes[role] = code
+			themeColorSpecs[role] = value
+		}
+	}
+
+	ColorMerged, ColorUnmerged, ColorWarning = codes["merged"], codes["unmerged"], codes["warning"]
+	ColorDim, ColorAccent, ColorRemote, ColorGone = codes["dim"], codes["accent"], codes["remote"], codes["gone"]
+	return invalid
+}
+
+// colorLegend names the colors of merged and unmerged branches as the theme set them
+func colorLegend(localizer *i18n.Localizer) string {
+	name := func(role string) string {
+		if code, _ := ansiCode(themeColorSpecs[role]); code == "" {
+			return localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "NoColorName"})
+		}
+		return strings.ToLower(themeColorSpecs[role])
+	}
+	legend, _ := localizer.Localize(&i18n.LocalizeConfig{
+		MessageID:    "FzfHeaderLegend",
+		TemplateData: map[string]interface{}{"Merged": name("merged"), "Unmerged": name("unmerged")},
+	})
+	return legend
+}
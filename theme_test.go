@@ -0,0 +1,44 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestColorLegend(t *testing.T) {
+	saved := []string{ColorMerged, ColorUnmerged, ColorWarning, ColorDim, ColorAccent, ColorRemote, ColorGone}
+	savedSpecs := maps.Clone(themeColorSpecs)
+	t.Cleanup(func() {
+		ColorMerged, ColorUnmerged, ColorWarning, ColorDim, ColorAccent, ColorRemote, ColorGone = saved[0], saved[1], saved[2], saved[3], saved[4], saved[5], saved[6]
+		themeColorSpecs = savedSpecs
+	})
+
+	tests := []struct {
+		name   string
+		theme  string
+		config map[string]string
+		lang   string
+		want   string
+	}{
+		{name: "default", theme: "default", lang: "en", want: "green = merged, red = unmerged"},
+		{name: "light", theme: "light", lang: "en", want: "blue = merged, red = unmerged"},
+		{name: "mono", theme: "mono", lang: "en", want: "no color = merged, bold = unmerged"},
+		{name: "configured colors", theme: "default", config: map[string]string{"merged": "Bold Cyan", "unmerged": "none"}, lang: "en",
+			want: "bold cyan = merged, no color = unmerged"},
+		{name: "japanese", theme: "mono", lang: "ja", want: "色なし = マージ済み, bold = 未マージ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitConfigRepo(t)
+			for role, value := range tt.config {
+				gitConfig(t, false, colorConfigSection+role, value)
+			}
+			if invalid := applyTheme(tt.theme); len(invalid) > 0 {
+				t.Fatalf("applyTheme(%q) reported %q", tt.theme, invalid)
+			}
+			if got := colorLegend(testLocalizer(t, tt.lang)); got != tt.want {
+				t.Errorf("colorLegend() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+)
+
+// getRemoteBranches returns the branches that currently exist on the remote, as reported by git ls-remote
+func getRemoteBranches(remote string) (map[string]bool, error) {
+	output, err := newCommand("git", "ls-remote", "--heads", remote).Output()
+	if err != nil {
+		return nil, err
+	}
+	branches := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		// Lines look like "<hash>\trefs/heads/<branch>"
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			branches[strings.TrimPrefix(ref, "refs/heads/")] = true
+		}
+	}
+	return branches, nil
+}
+
+// findStaleTrackingRefs returns the remote-tracking refs (e.g. "origin/feature/foo") of the given
+// branches that still exist locally although the branch was removed from the remote.
+// Remotes that cannot be reached are assumed to still have their branches.
+func findStaleTrackingRefs(branches []BranchInfo) []string {
+	remoteBranches := make(map[string]map[string]bool)
+	var stale []string
+	for _, b := range branches {
+		if !b.HasRemoteUpstream() || !refExists("refs/remotes/"+b.Upstream) {
+			continue
+		}
+		existing, ok := remoteBranches[b.UpstreamRemote]
+		if !ok {
+			var err error
+			existing, err = getRemoteBranches(b.UpstreamRemote)
+			if err != nil {
+				existing = nil
+			}
+			remoteBranches[b.UpstreamRemote] = existing
+		}
+		if existing != nil && !existing[b.UpstreamBranch] {
+			stale = append(stale, b.Upstream)
+		}
+	}
+	return stale
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// trashRefPrefix is the namespace branches are moved to by --trash
+const trashRefPrefix = "refs/trash/"
+
+// trashReflogPrefix starts the reflog message of a trash ref and is followed by the branch name
+const trashReflogPrefix = "git-delete-branch: trash "
+
+// trashEntry is a branch moved to the trash
+type trashEntry struct {
+	// Name is the ref without refs/trash/, e.g. "feature/foo-2" for the second trashed feature/foo
+	Name string
+	// Branch is the name the branch had
+	Branch  string
+	Hash    string
+	Trashed time.Time
+}
+
+// Ref returns the full refname of the entry
+func (e trashEntry) Ref() string {
+	return trashRefPrefix + e.Name
+}
+
+// Expired reports whether the entry was trashed at least age before now. An entry trashed
+// exactly at the threshold has expired.
+func (e trashEntry) Expired(now time.Time, age time.Duration) bool {
+	return !e.Trashed.After(now.Add(-age))
+}
+
+// trashBranch points a new trash ref at the branch tip and returns its name.
+// A numeric suffix is added when the branch was trashed before.
+func trashBranch(branch, hash string) (string, error) {
+	existing := existingRefs(trashRefPrefix)
+	name := branch
+	for n := 2; existing[trashRefPrefix+name]; n++ {
+		name = branch + "-" + strconv.Itoa(n)
+	}
+	// The reflog keeps when the branch was trashed and under which name
+	output, err := newCommand("git", "update-ref", "--create-reflog", "-m", trashReflogPrefix+branch, trashRefPrefix+name, hash, "").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return name, nil
+}
+
+// listTrash returns the trashed branches, oldest first
+func listTrash() ([]trashEntry, error) {
+	output, err := newCommand("git", "for-each-ref", "--format=%(refname)%00%(objectname)", trashRefPrefix).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, string(output))
+	}
+	var entries []trashEntry
+	for _, line := range strings.Split(string(output), "\n") {
+		ref, hash, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		entry := trashEntry{Name: strings.TrimPrefix(ref, trashRefPrefix), Hash: hash}
+		entry.Branch = entry.Name
+		if message, err := newCommand("git", "log", "-g", "-1", "--format=%gs", ref, "--").Output(); err == nil {
+			if branch, ok := strings.CutPrefix(strings.TrimSpace(string(message)), trashReflogPrefix); ok {
+				entry.Branch = branch
+			}
+		}
+		// Without a reflog the entry counts as trashed long ago
+		entry.Trashed, _ = getBranchCreationDate(ref)
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b trashEntry) int { return a.Trashed.Compare(b.Trashed) })
+	return entries, nil
+}
+
+// dropTrash deletes the trash ref together with its reflog
+func dropTrash(name string) error {
+	output, err := newCommand("git", "update-ref", "-d", trashRefPrefix+name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return nil
+}
+
+// restoreTrash recreates the branch of the entry and removes it from the trash
+func restoreTrash(entry trashEntry) error {
+	output, err := newCommand("git", "branch", entry.Branch, entry.Hash).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, string(output))
+	}
+	return dropTrash(entry.Name)
+}
+
+// runEmptyTrash implements the empty-trash subcommand, which drops trashed branches for good
+func runEmptyTrash(localizer *i18n.Localizer, args []string) {
+	emptyFlags := flag.NewFlagSet("empty-trash", flag.ExitOnError)
+	olderThanFlag := emptyFlags.String("older-than", "30d", "Only drop branches trashed longer ago than the duration, 0 drops all")
+	emptyFlags.Parse(args)
+
+	age, err := parseAgeDuration(*olderThanFlag)
+	if err != nil {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "InvalidDuration",
+			TemplateData: map[string]interface{}{"Flag": "--older-than", "Value": *olderThanFlag},
+		}))
+		os.Exit(1)
+	}
+	entries, err := listTrash()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing trash: %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	dropped := 0
+	for _, entry := range entries {
+		if !entry.Expired(now, age) {
+			continue
+		}
+		if err := dropTrash(entry.Name); err != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorDroppingTrash",
+				TemplateData: map[string]interface{}{"Ref": entry.Ref(), "Error": err},
+			}))
+			continue
+		}
+		dropped++
+	}
+	fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+		MessageID:    "TrashEmptied",
+		TemplateData: map[string]interface{}{"Count": dropped, "Remaining": len(entries) - dropped},
+	}))
+	os.Exit(0)
+}
+
+// runShowTrash lists the trashed branches in fzf and restores the selected ones
+func runShowTrash(localizer *i18n.Localizer, yes bool) {
+	entries, err := listTrash()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing trash: %v\n", err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "TrashIsEmpty"}))
+		os.Exit(0)
+	}
+
+	// The trash ref name is the key the selection maps back to its entry with
+	byName := make(map[string]trashEntry)
+	var items []string
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+		trashed := "-"
+		if !entry.Trashed.IsZero() {
+			trashed = entry.Trashed.Format("2006-01-02 15:04")
+		}
+		items = append(items, keyedLine(entry.Name, fmt.Sprintf("%s%s%s %s%s %s%s", ColorAccent, entry.Name, ColorReset, ColorDim, trashed, entry.Hash[:min(8, len(entry.Hash))], ColorReset)))
+	}
+	header := localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "TrashPickerHeader"})
+	fzfArgs := append(fzfColorArgs(), fzfKeyArgs...)
+	selected, err := runFzf(append(fzfArgs, "--multi", "--header", header, "--preview", "git log "+gitColorOption()+" "+trashRefPrefix+"{1}"), items)
+	if err == errFzfCancelled {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running fzf: %v\n", err)
+		os.Exit(1)
+	}
+
+	var toRestore []trashEntry
+	for _, item := range selected {
+		if entry, ok := byName[lineKey(item)]; ok {
+			toRestore = append(toRestore, entry)
+		}
+	}
+	if len(toRestore) == 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "NoBranchesSelected"}))
+		os.Exit(0)
+	}
+
+	if !yes {
+		confirm := false
+		prompt := &survey.Confirm{
+			Message: localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "RestoreTrashPrompt",
+				TemplateData: map[string]interface{}{"Count": len(toRestore)},
+			}),
+		}
+		survey.AskOne(prompt, &confirm)
+		if !confirm {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "DeletionCancelled"}))
+			os.Exit(0)
+		}
+	}
+
+	failed := false
+	for _, entry := range toRestore {
+		if refExists("refs/heads/" + entry.Branch) {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "SkippingExistingBranch",
+				TemplateData: map[string]interface{}{"Branch": entry.Branch},
+			}))
+			continue
+		}
+		if err := restoreTrash(entry); err != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorRestoringBranch",
+				TemplateData: map[string]interface{}{"Branch": entry.Branch, "Error": err},
+			}))
+			failed = true
+			continue
+		}
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "BranchRestored",
+			TemplateData: map[string]interface{}{"Branch": entry.Branch, "Hash": entry.Hash[:min(8, len(entry.Hash))]},
+		}))
+	}
+	if failed {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrashEntryExpired(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	ttl := 30 * 24 * time.Hour
+	tests := []struct {
+		name    string
+		trashed time.Time
+		age     time.Duration
+		want    bool
+	}{
+		{"exactly at the ttl", now.Add(-ttl), ttl, true},
+		{"one second before the ttl", now.Add(-ttl + time.Second), ttl, false},
+		{"one second after the ttl", now.Add(-ttl - time.Second), ttl, true},
+		{"just trashed", now, ttl, false},
+		{"zero age drops everything", now, 0, true},
+		{"trashed in the future", now.Add(time.Hour), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := trashEntry{Name: "feature/foo", Branch: "feature/foo", Trashed: tt.trashed}
+			if got := entry.Expired(now, tt.age); got != tt.want {
+				t.Errorf("Expired(%v, %v) for an entry trashed at %v = %v, want %v", now, tt.age, tt.trashed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAgeDurationForTrash(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseAgeDuration(tt.value)
+		if err != nil {
+			t.Errorf("parseAgeDuration(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAgeDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// How often the built-in picker checks whether the terminal was resized while waiting for a key
+const tuiResizePollInterval = 200 * time.Millisecond
+
+// Terminals narrower than this show the built-in picker without the preview pane
+const tuiMinPreviewWidth = 60
+
+// tuiPicker is the state of the built-in full-screen picker of --tui
+type tuiPicker struct {
+	// fzf lines, see keyedLine. Lines without a key are shown but cannot be selected.
+	items    []string
+	prompt   string
+	help     string
+	preview  func(branch string) string
+	previews map[string][]string
+
+	filter   string
+	matches  []int // indexes into items that match the filter
+	cursor   int   // index into matches
+	offset   int   // first match shown
+	selected map[int]bool
+	width    int
+	height   int
+}
+
+// runTUI lets the user pick items in a full-screen list drawn on the terminal, with a preview
+// pane showing what preview returns for the highlighted branch. Typing filters the list, Space
+// or Tab toggles the highlighted line, ctrl-a/ctrl-d select or deselect all matching lines and
+// Enter confirms. The list starts filtered by query, with the lines whose key is in preselected
+// selected. Like runFzfWithQuery it returns the filter and the selected lines, or errFzfCancelled
+// for Esc and ctrl-c. The screen is restored before it returns.
+func runTUI(items []string, query string, preselected map[string]bool, prompt, help string, preview func(branch string) string) (string, []string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	defer tty.Close()
+
+	// Fd would switch the file to blocking mode, which would stop the read deadlines from working
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return "", nil, err
+	}
+	var state *term.State
+	var rawErr error
+	if err := conn.Control(func(fd uintptr) { state, rawErr = term.MakeRaw(int(fd)) }); err != nil {
+		return "", nil, err
+	}
+	if rawErr != nil {
+		return "", nil, rawErr
+	}
+	size := func() (width, height int) {
+		conn.Control(func(fd uintptr) { width, height, _ = term.GetSize(int(fd)) })
+		return width, height
+	}
+
+	// The alternate screen keeps the scrollback as it was
+	fmt.Fprint(tty, "\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Fprint(tty, "\033[?25h\033[?1049l")
+		conn.Control(func(fd uintptr) { term.Restore(int(fd), state) })
+	}()
+
+	p := &tuiPicker{
+		items:    items,
+		prompt:   prompt,
+		help:     help,
+		preview:  preview,
+		previews: make(map[string][]string),
+		selected: make(map[int]bool),
+		filter:   query,
+	}
+	for i, item := range items {
+		if key := lineKey(item); key != "" && preselected[key] {
+			p.selected[i] = true
+		}
+	}
+	p.width, p.height = size()
+	p.applyFilter()
+	tty.Write(p.render())
+
+	buf := make([]byte, 256)
+	for {
+		// Without deadline support the picker simply redraws at the next key after a resize
+		deadline := tty.SetReadDeadline(time.Now().Add(tuiResizePollInterval)) == nil
+		n, err := tty.Read(buf)
+		if err != nil && !(deadline && errors.Is(err, os.ErrDeadlineExceeded)) {
+			return "", nil, err
+		}
+		if width, height := size(); width != p.width || height != p.height {
+			p.width, p.height = width, height
+			p.scrollToCursor()
+		} else if n == 0 {
+			continue
+		}
+		done, cancelled := p.handleKeys(buf[:n])
+		if cancelled {
+			return "", nil, errFzfCancelled
+		}
+		if done {
+			return p.filter, p.selection(), nil
+		}
+		tty.Write(p.render())
+	}
+}
+
+// handleKeys applies the keys read from the terminal. It reports whether the selection was
+// confirmed or cancelled.
+func (p *tuiPicker) handleKeys(input []byte) (done, cancelled bool) {
+	for len(input) > 0 {
+		// Escape sequences of the arrow and page keys
+		sequences := map[string]func(){
+			"\033[A":  func() { p.moveCursor(-1) },
+			"\033OA":  func() { p.moveCursor(-1) },
+			"\033[B":  func() { p.moveCursor(1) },
+			"\033OB":  func() { p.moveCursor(1) },
+			"\033[5~": func() { p.moveCursor(-p.listHeight()) },
+			"\033[6~": func() { p.moveCursor(p.listHeight()) },
+		}
+		matched := false
+		for sequence, action := range sequences {
+			if bytes.HasPrefix(input, []byte(sequence)) {
+				action()
+				input = input[len(sequence):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch input[0] {
+		case 3: // ctrl-c
+			return false, true
+		case 27: // Esc, or an escape sequence that is not bound
+			if len(input) == 1 {
+				return false, true
+			}
+			// Skip the unknown sequence. Control sequences end with a byte from @ to ~.
+			end := 2
+			if input[1] == '[' {
+				for end < len(input) && (input[end] < '@' || input[end] > '~') {
+					end++
+				}
+				end++
+			}
+			input = input[min(end, len(input)):]
+			continue
+		case '\r', '\n':
+			return true, false
+		case ' ', '\t':
+			p.toggle()
+		case 1: // ctrl-a
+			for _, i := range p.matches {
+				if lineKey(p.items[i]) != "" {
+					p.selected[i] = true
+				}
+			}
+		case 4: // ctrl-d
+			clear(p.selected)
+		case 14: // ctrl-n
+			p.moveCursor(1)
+		case 16: // ctrl-p
+			p.moveCursor(-1)
+		case 21: // ctrl-u
+			p.filter = ""
+			p.applyFilter()
+		case 127, 8: // Backspace
+			if p.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(p.filter)
+				p.filter = p.filter[:len(p.filter)-size]
+				p.applyFilter()
+			}
+		default:
+			r, size := utf8.DecodeRune(input)
+			if r >= ' ' && r != utf8.RuneError {
+				p.filter += string(r)
+				p.applyFilter()
+			}
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return false, false
+}
+
+// applyFilter lists the items whose text contains the filter, ignoring case and colors.
+// Dividers and headers are only shown without a filter.
+func (p *tuiPicker) applyFilter() {
+	p.matches = p.matches[:0]
+	filter := strings.ToLower(p.filter)
+	for i, item := range p.items {
+		if filter == "" {
+			p.matches = append(p.matches, i)
+			continue
+		}
+		text := strings.ToLower(ansiStripper.ReplaceAllString(lineText(item), ""))
+		if lineKey(item) != "" && strings.Contains(text, filter) {
+			p.matches = append(p.matches, i)
+		}
+	}
+	p.cursor = 0
+	p.offset = 0
+}
+
+// moveCursor moves the highlighted line by delta matches
+func (p *tuiPicker) moveCursor(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.cursor = max(0, min(len(p.matches)-1, p.cursor+delta))
+	p.scrollToCursor()
+}
+
+// scrollToCursor scrolls the list so the highlighted line is visible
+func (p *tuiPicker) scrollToCursor() {
+	height := p.listHeight()
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+height {
+		p.offset = p.cursor - height + 1
+	}
+}
+
+// toggle selects or deselects the highlighted line and moves on to the next one
+func (p *tuiPicker) toggle() {
+	if len(p.matches) == 0 {
+		return
+	}
+	i := p.matches[p.cursor]
+	if lineKey(p.items[i]) != "" {
+		p.selected[i] = !p.selected[i]
+		if !p.selected[i] {
+			delete(p.selected, i)
+		}
+	}
+	p.moveCursor(1)
+}
+
+// selection returns the selected lines in list order. Without a selection the highlighted
+// line is taken, as fzf does.
+func (p *tuiPicker) selection() []string {
+	var lines []string
+	for i, item := range p.items {
+		if p.selected[i] {
+			lines = append(lines, item)
+		}
+	}
+	if len(lines) == 0 && len(p.matches) > 0 {
+		if item := p.items[p.matches[p.cursor]]; lineKey(item) != "" {
+			lines = append(lines, item)
+		}
+	}
+	return lines
+}
+
+// listHeight returns the number of list lines between the prompt and the help line
+func (p *tuiPicker) listHeight() int {
+	return max(p.height-2, 1)
+}
+
+// previewLines returns the preview of the branch split into lines, running preview only once
+func (p *tuiPicker) previewLines(branch string) []string {
+	if lines, ok := p.previews[branch]; ok {
+		return lines
+	}
+	lines := strings.Split(strings.TrimRight(p.preview(branch), "\n"), "\n")
+	p.previews[branch] = lines
+	return lines
+}
+
+// render draws the whole screen: the prompt with the filter, the list next to the preview of
+// the highlighted branch, and the key help
+func (p *tuiPicker) render() []byte {
+	var b bytes.Buffer
+	b.WriteString("\033[H")
+	line := func(s string, width int) {
+		s = truncateCell(s, width)
+		b.WriteString(s + strings.Repeat(" ", max(width-cellWidth(s), 0)))
+	}
+
+	counts := fmt.Sprintf("  %d/%d (%d)", len(p.matches), len(p.items), len(p.selected))
+	line(p.prompt+p.filter+ColorDim+counts+ColorReset, p.width)
+	b.WriteString("\r\n")
+
+	listWidth := p.width
+	var previewLines []string
+	if p.width >= tuiMinPreviewWidth && p.preview != nil {
+		listWidth = p.width / 2
+		if len(p.matches) > 0 {
+			if branch := lineKey(p.items[p.matches[p.cursor]]); branch != "" {
+				previewLines = p.previewLines(branch)
+			}
+		}
+	}
+
+	for row := 0; row < p.listHeight(); row++ {
+		text := ""
+		if i := p.offset + row; i < len(p.matches) {
+			item := p.matches[i]
+			marker := "  "
+			if i == p.cursor {
+				marker = "> "
+			}
+			if p.selected[item] {
+				marker = marker[:1] + "*"
+			}
+			text = marker + " " + lineText(p.items[item])
+		}
+		line(text, listWidth)
+		if listWidth < p.width {
+			b.WriteString(ColorDim + "│" + ColorReset)
+			previewText := ""
+			if row < len(previewLines) {
+				previewText = previewLines[row]
+			}
+			line(previewText, p.width-listWidth-1)
+		}
+		b.WriteString("\r\n")
+	}
+	line(ColorDim+p.help+ColorReset, p.width)
+	return b.Bytes()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// runUndo implements the undo subcommand, which restores the branches deleted in a previous run
+func runUndo(localizer *i18n.Localizer, args []string) {
+	undoFlags := flag.NewFlagSet("undo", flag.ExitOnError)
+	listFlag := undoFlags.Bool("list", false, "List the sessions that can be restored")
+	sessionFlag := undoFlags.String("session", "", "Restore the given session instead of the most recent one")
+	undoFlags.Parse(args)
+
+	sessions, err := listBackupSessions()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing backups: %v\n", err)
+		os.Exit(1)
+	}
+	if len(sessions) == 0 {
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "NoBackupSessions"}))
+		os.Exit(0)
+	}
+
+	if *listFlag {
+		for _, session := range sessions {
+			var names []string
+			for _, entry := range session.Entries {
+				names = append(names, entry.Name)
+			}
+			fmt.Printf("%s  %s\n", session.ID, strings.Join(names, ", "))
+		}
+		os.Exit(0)
+	}
+
+	session := sessions[0]
+	if *sessionFlag != "" {
+		found := false
+		for _, s := range sessions {
+			if s.ID == *sessionFlag {
+				session, found = s, true
+				break
+			}
+		}
+		if !found {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "UnknownBackupSession",
+				TemplateData: map[string]interface{}{"Session": *sessionFlag},
+			}))
+			os.Exit(1)
+		}
+	}
+
+	for _, entry := range session.Entries {
+		if refExists(entry.Ref) {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "SkippingExistingBranch",
+				TemplateData: map[string]interface{}{"Branch": entry.Name},
+			}))
+			continue
+		}
+		if err := restoreBackup(session.ID, entry); err != nil {
+			fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+				MessageID:    "ErrorRestoringBranch",
+				TemplateData: map[string]interface{}{"Branch": entry.Name, "Error": err},
+			}))
+			continue
+		}
+		fmt.Println(localizer.MustLocalize(&i18n.LocalizeConfig{
+			MessageID:    "BranchRestored",
+			TemplateData: map[string]interface{}{"Branch": entry.Name, "Hash": entry.Hash[:min(8, len(entry.Hash))]},
+		}))
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// runeWidth returns the number of terminal columns r occupies: 2 for East Asian wide and
+// fullwidth characters, 0 for control characters, combining and enclosing marks (such as
+// the voiced sound marks of decomposed kana) and format characters (such as zero-width
+// spaces, joiners and variation selectors), 1 otherwise
+func runeWidth(r rune) int {
+	if unicode.In(r, unicode.Cc, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// displayWidth returns the number of terminal columns s occupies
+func displayWidth(s string) int {
+	total := 0
+	for _, r := range s {
+		total += runeWidth(r)
+	}
+	return total
+}
+
+// truncateWidth shortens s to at most max columns, ending it with an ellipsis when cut
+func truncateWidth(s string, max int) string {
+	if displayWidth(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > max-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "…"
+}
+
+// padWidth truncates s to exactly columns wide and pads it with spaces on the right
+func padWidth(s string, columns int) string {
+	s = truncateWidth(s, columns)
+	return s + strings.Repeat(" ", columns-displayWidth(s))
+}
@@ -0,0 +1,95 @@
+package main
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"main", 4},
+		{"機能", 4},
+		{"feature/機能", 12},
+		{"ブランチ-1", 10},
+		{"ＡＢ", 4},           // fullwidth Latin
+		{"caf\u00e9", 4},    // precomposed é
+		{"e\u0301", 1},      // e with a combining acute accent
+		{"cafe\u0301/x", 6}, // combining mark inside ASCII
+		{"한국어", 6},
+		{"a\u200bb", 2},                       // zero width space
+		{"αβγ", 3},                            // ambiguous width counts as narrow
+		{"\u30ab\u3099\u30a4", 4},             // decomposed ガイ, katakana with a combining voiced sound mark
+		{"\u304f\u3099\u308b\u30fc\u3077", 8}, // decomposed ぐるーぷ
+		{"\u30cf\u309a\u30b9", 4},             // decomposed パス, semi-voiced sound mark
+		{"a\u20d7", 1},                        // combining arrow above
+		{"1\u20e3", 1},                        // combining enclosing keycap
+		{"\u2764\ufe0f", 1},                   // variation selector
+		{"\u845b\U000e0100", 2},               // ideographic variation selector
+		{"a\u200db", 2},                       // zero width joiner
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.s); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{"fits", "main", 4, "main"},
+		{"ascii cut", "feature/login", 8, "feature…"},
+		{"wide fits exactly", "機能ab", 6, "機能ab"},
+		{"wide cut at the boundary", "機能ブランチ", 7, "機能ブ…"},
+		// A wide character that would end one column past the limit is dropped whole
+		{"wide cut before the boundary", "機能ブランチ", 6, "機能…"},
+		{"mixed ascii and wide", "feat/機能", 7, "feat/…"},
+		{"mixed wide and ascii", "機能/feature", 7, "機能/f…"},
+		{"combining mark stays with its letter", "cafe\u0301-au-lait", 5, "cafe\u0301…"},
+		{"combining mark fits", "cafe\u0301", 4, "cafe\u0301"},
+		{"decomposed kana fits", "\u30d5\u3099\u30e9\u30f3\u30c1", 10, "\u30d5\u3099\u30e9\u30f3\u30c1"},
+		{"decomposed kana keeps its mark", "\u30d5\u3099\u30e9\u30f3\u30c1", 5, "\u30d5\u3099\u30e9\u2026"},
+		{"one column", "機能", 1, "…"},
+		{"zero columns", "main", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateWidth(tt.s, tt.max)
+			if got != tt.want {
+				t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+			}
+			if width := displayWidth(got); width > tt.max {
+				t.Errorf("truncateWidth(%q, %d) is %d columns wide", tt.s, tt.max, width)
+			}
+		})
+	}
+}
+
+func TestPadWidth(t *testing.T) {
+	tests := []struct {
+		s       string
+		columns int
+		want    string
+	}{
+		{"Me", 6, "Me    "},
+		{"山田", 6, "山田  "},
+		{"山田太郎です", 6, "山田… "},
+		{"Jose\u0301", 6, "Jose\u0301  "},
+		{"\u3055\u3099\u3068\u3046", 8, "\u3055\u3099\u3068\u3046  "}, // decomposed ざとう
+		{"alexander", 6, "alexa…"},
+	}
+	for _, tt := range tests {
+		got := padWidth(tt.s, tt.columns)
+		if got != tt.want {
+			t.Errorf("padWidth(%q, %d) = %q, want %q", tt.s, tt.columns, got, tt.want)
+		}
+		if width := displayWidth(got); width != tt.columns {
+			t.Errorf("padWidth(%q, %d) is %d columns wide", tt.s, tt.columns, width)
+		}
+	}
+}
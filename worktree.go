@@ -0,0 +1,25 @@
+package main
+
+import (
+	"strings"
+)
+
+// getWorktreeBranches returns a map from branch name to the path of the worktree it is checked out in
+func getWorktreeBranches() (map[string]string, error) {
+	cmd := newCommand("git", "worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := make(map[string]string)
+	var path string
+	for _, line := range strings.Split(string(output), "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+		} else if ref, ok := strings.CutPrefix(line, "branch "); ok {
+			worktrees[strings.TrimPrefix(ref, "refs/heads/")] = path
+		}
+	}
+	return worktrees, nil
+}